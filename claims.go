@@ -3,10 +3,21 @@ package jwt
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"time"
 )
 
+// ErrTokenExpired is returned when the exp claim is in the past.
+var ErrTokenExpired = errors.New("Token is expired")
+
+// ErrTokenNotValidYet is returned when the nbf claim is in the future.
+var ErrTokenNotValidYet = errors.New("Token is not valid yet")
+
+// ErrTokenUsedBeforeIssued is returned when the iat claim is in the future.
+var ErrTokenUsedBeforeIssued = errors.New("Token used before issued")
+
 // Claims represents a JWT claims section.
 type Claims struct {
 	values map[string]interface{}
@@ -23,12 +34,15 @@ func (claims *Claims) Has(name string) bool {
 	return exists
 }
 
-// SetExpiration sets the expiration timestamp for the Claims.
+// SetExpiration sets the expiration timestamp for the Claims. Timestamps
+// are stored as RFC 7519 NumericDate values, the number of seconds since
+// the epoch, so any fraction of a second is dropped.
 func (claims *Claims) SetExpiration(exp time.Time) {
-	claims.values["exp"] = exp.UnixNano()
+	claims.values["exp"] = exp.Unix()
 }
 
-// GetExpiration gets the expiration timestamp for the Claims.
+// GetExpiration gets the expiration timestamp for the Claims. NumericDate
+// values with fractional seconds are accepted.
 func (claims *Claims) GetExpiration() (time.Time, error) {
 	zeroDate := time.Unix(0, 0)
 	errMsg := "jwt: Claims.GetExpiration: %v"
@@ -37,16 +51,17 @@ func (claims *Claims) GetExpiration() (time.Time, error) {
 	if !exists {
 		return zeroDate, fmt.Errorf(errMsg, "No such value expt")
 	}
-	nsecs, validType := value.(int64)
+	date, validType := parseNumericDate(value)
 	if !validType {
 		return zeroDate, fmt.Errorf(errMsg, "Invalid exp value")
 	}
-	return time.Unix(0, nsecs), nil
+	return date, nil
 }
 
-// SetNotBefore sets the not before timestamp for the Claims.
+// SetNotBefore sets the not before timestamp for the Claims in seconds
+// since the epoch.
 func (claims *Claims) SetNotBefore(nbf time.Time) {
-	claims.values["nbf"] = nbf.UnixNano()
+	claims.values["nbf"] = nbf.Unix()
 }
 
 // GetNotBefore gets the not before timestamp for the Claims.
@@ -58,16 +73,17 @@ func (claims *Claims) GetNotBefore() (time.Time, error) {
 	if !exists {
 		return zeroDate, fmt.Errorf(errMsg, "No such value nbf")
 	}
-	nsecs, validType := value.(int64)
+	date, validType := parseNumericDate(value)
 	if !validType {
 		return zeroDate, fmt.Errorf(errMsg, "Invalid nbf value")
 	}
-	return time.Unix(0, nsecs), nil
+	return date, nil
 }
 
-// SetIssuedAt sets the issued at timestamp for the Claims.
+// SetIssuedAt sets the issued at timestamp for the Claims in seconds
+// since the epoch.
 func (claims *Claims) SetIssuedAt(iat time.Time) {
-	claims.values["iat"] = iat.UnixNano()
+	claims.values["iat"] = iat.Unix()
 }
 
 // GetIssuedAt gets the issued at timestamp for the Claims.
@@ -79,11 +95,11 @@ func (claims *Claims) GetIssuedAt() (time.Time, error) {
 	if !exists {
 		return zeroDate, fmt.Errorf(errMsg, "No such value iat")
 	}
-	nsecs, validType := value.(int64)
+	date, validType := parseNumericDate(value)
 	if !validType {
 		return zeroDate, fmt.Errorf(errMsg, "Invalid iat value")
 	}
-	return time.Unix(0, nsecs), nil
+	return date, nil
 }
 
 // SetIssuer sets the issuer for the Claims.
@@ -110,28 +126,146 @@ func (claims *Claims) SetAudience(aud string) {
 	claims.values["aud"] = aud
 }
 
-// GetAudience gets the audience for the Claims.
+// GetAudience gets the audience for the Claims. An aud array is accepted
+// only if it has exactly one value. Use GetAudiences to get every audience.
 func (claims *Claims) GetAudience() (string, error) {
 	errMsg := "jwt: Claims.GetAudience: %v"
 	value, exists := claims.values["aud"]
 	if !exists {
 		return "", fmt.Errorf(errMsg, "No such value aud")
 	}
-	str, validType := value.(string)
+	if str, validType := value.(string); validType {
+		return str, nil
+	}
+	auds, validType := audienceList(value)
 	if !validType {
 		return "", fmt.Errorf(errMsg, "Invalid aud value")
 	}
+	if len(auds) == 0 {
+		return "", fmt.Errorf(errMsg, "aud is empty")
+	}
+	if len(auds) != 1 {
+		return "", fmt.Errorf(errMsg, "aud has multiple values")
+	}
+	return auds[0], nil
+}
+
+// SetAudiences sets a list of audiences for the Claims. The aud claim is
+// encoded as a JSON array. An empty list deletes the aud claim.
+func (claims *Claims) SetAudiences(auds []string) {
+	if len(auds) == 0 {
+		delete(claims.values, "aud")
+		return
+	}
+	list := make([]string, len(auds))
+	copy(list, auds)
+	claims.values["aud"] = list
+}
+
+// GetAudiences gets every audience for the Claims. The aud claim can be a
+// single string or an array of strings.
+func (claims *Claims) GetAudiences() ([]string, error) {
+	errMsg := "jwt: Claims.GetAudiences: %v"
+	value, exists := claims.values["aud"]
+	if !exists {
+		return nil, fmt.Errorf(errMsg, "No such value aud")
+	}
+	auds, validType := audienceList(value)
+	if !validType {
+		return nil, fmt.Errorf(errMsg, "Invalid aud value")
+	}
+	return auds, nil
+}
+
+// HasAudience returns true if aud is one of the audiences for the Claims.
+func (claims *Claims) HasAudience(aud string) bool {
+	auds, err := claims.GetAudiences()
+	if err != nil {
+		return false
+	}
+	for _, value := range auds {
+		if value == aud {
+			return true
+		}
+	}
+	return false
+}
+
+func audienceList(value interface{}) ([]string, bool) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, true
+	case []string:
+		auds := make([]string, len(v))
+		copy(auds, v)
+		return auds, true
+	case []interface{}:
+		auds := make([]string, 0, len(v))
+		for _, item := range v {
+			str, validType := item.(string)
+			if !validType {
+				return nil, false
+			}
+			auds = append(auds, str)
+		}
+		return auds, true
+	default:
+		return nil, false
+	}
+}
+
+// SetSubject sets the subject for the Claims.
+func (claims *Claims) SetSubject(sub string) {
+	claims.values["sub"] = sub
+}
+
+// GetSubject gets the subject for the Claims.
+func (claims *Claims) GetSubject() (string, error) {
+	errMsg := "jwt: Claims.GetSubject: %v"
+	value, exists := claims.values["sub"]
+	if !exists {
+		return "", fmt.Errorf(errMsg, "No such value sub")
+	}
+	str, validType := value.(string)
+	if !validType {
+		return "", fmt.Errorf(errMsg, "Invalid sub value")
+	}
+	return str, nil
+}
+
+// GetSubjectOrPrincipal gets the subject for the Claims. If there is no sub
+// claim the legacy prn claim is used instead. This allows tokens issued
+// before the sub claim was adopted to keep working.
+func (claims *Claims) GetSubjectOrPrincipal() (string, error) {
+	errMsg := "jwt: Claims.GetSubjectOrPrincipal: %v"
+	name := "sub"
+	if !claims.Has(name) {
+		name = "prn"
+	}
+	value, exists := claims.values[name]
+	if !exists {
+		return "", fmt.Errorf(errMsg, "No such value sub or prn")
+	}
+	str, validType := value.(string)
+	if !validType {
+		return "", fmt.Errorf(errMsg, "Invalid "+name+" value")
+	}
 	return str, nil
 }
 
 // SetPrincipal sets the principal for the Claims.
+//
+// Deprecated: prn predates RFC 7519. Use SetSubject instead.
 func (claims *Claims) SetPrincipal(prn string) {
 	claims.values["prn"] = prn
 }
 
 // GetPrincipal gets the principal for the Claims.
+//
+// Deprecated: prn predates RFC 7519. Use GetSubject or GetSubjectOrPrincipal
+// instead.
 func (claims *Claims) GetPrincipal() (string, error) {
-	errMsg := "jwt: Claims.GetAudience: %v"
+	errMsg := "jwt: Claims.GetPrincipal: %v"
 	value, exists := claims.values["prn"]
 	if !exists {
 		return "", fmt.Errorf(errMsg, "No such value prn")
@@ -181,6 +315,125 @@ func (claims *Claims) GetType() (string, error) {
 	return str, nil
 }
 
+// ValidateTime validates the exp, nbf, and iat claims against now. The leeway
+// parameter is the clock skew allowed between the issuer and now. Claims that
+// are not present are not validated. The returned error wraps ErrTokenExpired,
+// ErrTokenNotValidYet, or ErrTokenUsedBeforeIssued when a claim is not valid.
+func (claims *Claims) ValidateTime(now time.Time, leeway time.Duration) error {
+	errMsg := "jwt: Claims.ValidateTime: %w"
+	for _, name := range []string{"exp", "nbf", "iat"} {
+		if err := claims.validateTimeClaim(name, now, leeway); err != nil {
+			return fmt.Errorf(errMsg, err)
+		}
+	}
+	return nil
+}
+
+// validateTimeClaim validates the exp, nbf, or iat claim given by name
+// against now. It returns nil if the claim is not present.
+func (claims *Claims) validateTimeClaim(name string, now time.Time, leeway time.Duration) error {
+	date, exists, err := claims.numericDate(name)
+	if err != nil || !exists {
+		return err
+	}
+	switch name {
+	case "exp":
+		if !now.Before(date.Add(leeway)) {
+			return ErrTokenExpired
+		}
+	case "nbf":
+		if now.Add(leeway).Before(date) {
+			return ErrTokenNotValidYet
+		}
+	case "iat":
+		if now.Add(leeway).Before(date) {
+			return ErrTokenUsedBeforeIssued
+		}
+	}
+	return nil
+}
+
+// numericDate gets the timestamp claim given by name.
+func (claims *Claims) numericDate(name string) (time.Time, bool, error) {
+	value, exists := claims.values[name]
+	if !exists {
+		return time.Time{}, false, nil
+	}
+	date, validType := parseNumericDate(value)
+	if !validType {
+		return time.Time{}, true, fmt.Errorf("%w: %v is not a NumericDate", ErrInvalidClaim, name)
+	}
+	return date, true, nil
+}
+
+// NumericDate values outside of the years 1 to 9999 are rejected. Larger
+// values overflow once they are converted to a time.Time and compared.
+const (
+	minNumericDate = -62135596800
+	maxNumericDate = 253402300799
+)
+
+// parseNumericDate converts an RFC 7519 NumericDate to a time. The value is
+// an int64 when set through the Claims setters, a float64 once it has been
+// unmarshalled from JSON, and a json.Number when decoded with UseNumber.
+// Fractional seconds are kept.
+func parseNumericDate(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case int64:
+		return intNumericDate(v)
+	case int:
+		return intNumericDate(int64(v))
+	case int32:
+		return intNumericDate(int64(v))
+	case uint32:
+		return intNumericDate(int64(v))
+	case uint64:
+		if v > maxNumericDate {
+			return time.Time{}, false
+		}
+		return intNumericDate(int64(v))
+	case float32:
+		return floatNumericDate(float64(v))
+	case float64:
+		return floatNumericDate(v)
+	case json.Number:
+		if secs, err := v.Int64(); err == nil {
+			return intNumericDate(secs)
+		}
+		secs, err := v.Float64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		return floatNumericDate(secs)
+	default:
+		return time.Time{}, false
+	}
+}
+
+// intNumericDate converts seconds to a time. Values outside of the range of
+// NumericDate values that can be compared safely are rejected.
+func intNumericDate(secs int64) (time.Time, bool) {
+	if secs < minNumericDate || secs > maxNumericDate {
+		return time.Time{}, false
+	}
+	return time.Unix(secs, 0), true
+}
+
+// floatNumericDate converts fractional seconds to a time. Values that are
+// not finite or are outside of the range of NumericDate values that can be
+// compared safely are rejected.
+func floatNumericDate(value float64) (time.Time, bool) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return time.Time{}, false
+	}
+	secs := math.Floor(value)
+	if secs < minNumericDate || secs > maxNumericDate {
+		return time.Time{}, false
+	}
+	nsecs := math.Round((value - secs) * 1e9)
+	return time.Unix(int64(secs), int64(nsecs)), true
+}
+
 // Del Deletes value in the Claims.
 func (claims *Claims) Del(name string) {
 	delete(claims.values, name)
@@ -328,12 +581,21 @@ func (claims *Claims) Marshal() ([]byte, error) {
 	return bytes, err
 }
 
-// Unmarshal decodes the Claims values into a Claims object.
+// Unmarshal decodes the Claims values into a Claims object. The decoded
+// values are merged with the values already in the Claims. Use Parse to get
+// a JWT containing only the decoded values.
 func (claims *Claims) Unmarshal(bytes []byte) error {
 	errMsg := "jwt: Claims.Marshal: %v"
-	err := json.Unmarshal(bytes, &claims.values)
+	var values map[string]interface{}
+	err := json.Unmarshal(bytes, &values)
 	if err != nil {
 		return fmt.Errorf(errMsg, err)
 	}
+	if values == nil {
+		return fmt.Errorf(errMsg, "Claims is not a JSON object")
+	}
+	for key, value := range values {
+		claims.values[key] = value
+	}
 	return nil
 }
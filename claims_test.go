@@ -1,6 +1,10 @@
 package jwt
 
 import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
 	"testing"
 	"time"
 )
@@ -69,7 +73,7 @@ func TestClaims(test *testing.T) {
 
 func TestReservedTimeClaims(test *testing.T) {
 	claims := NewClaims()
-	now := time.Now()
+	now := time.Now().Truncate(time.Second)
 	claims.SetIssuedAt(now)
 	claims.SetExpiration(now)
 	claims.SetNotBefore(now)
@@ -92,6 +96,221 @@ func TestReservedTimeClaims(test *testing.T) {
 	}
 
 }
+func TestNumericDateClaims(test *testing.T) {
+	claims := NewClaims()
+	now := time.Now()
+	claims.SetExpiration(now)
+	if exp, _ := claims.Get("exp"); exp != now.Unix() {
+		test.Errorf("Expected exp to be %v seconds, but got %v instead", now.Unix(), exp)
+	}
+
+	err := claims.Unmarshal([]byte(`{"exp":1516239022,"nbf":1516239022.25,"iat":1516239022}`))
+	if err != nil {
+		test.Fatalf("Unable to decode JSON claims: %v", err)
+	}
+	expected := time.Unix(1516239022, 0)
+	if t, err := claims.GetExpiration(); err != nil {
+		test.Error(err.Error())
+	} else if !t.Equal(expected) {
+		test.Errorf("Expected %v, but got %v instead", expected, t)
+	}
+	if t, err := claims.GetIssuedAt(); err != nil {
+		test.Error(err.Error())
+	} else if !t.Equal(expected) {
+		test.Errorf("Expected %v, but got %v instead", expected, t)
+	}
+	expected = expected.Add(250 * time.Millisecond)
+	if t, err := claims.GetNotBefore(); err != nil {
+		test.Error(err.Error())
+	} else if !t.Equal(expected) {
+		test.Errorf("Expected %v, but got %v instead", expected, t)
+	}
+}
+
+func TestNumericDateTypes(test *testing.T) {
+	expected := time.Unix(1516239022, 0)
+	values := []interface{}{
+		int64(1516239022),
+		int(1516239022),
+		int32(1516239022),
+		uint32(1516239022),
+		uint64(1516239022),
+		float64(1516239022),
+		json.Number("1516239022"),
+		json.Number("1516239022.0"),
+	}
+	for _, value := range values {
+		claims := NewClaims()
+		claims.values["exp"] = value
+		if t, err := claims.GetExpiration(); err != nil {
+			test.Errorf("Failed to get exp from %T: %v", value, err)
+		} else if !t.Equal(expected) {
+			test.Errorf("Expected %v from %T, but got %v instead", expected, value, t)
+		}
+	}
+
+	invalid := []interface{}{
+		json.Number("soon"),
+		uint64(1 << 63),
+		"1516239022",
+		true,
+		float64(1e300),
+		float64(-1e300),
+		float64(1e19),
+		json.Number("1e19"),
+		math.NaN(),
+		math.Inf(1),
+		int64(9223372036000000000),
+		uint64(9223372036000000000),
+		json.Number("9223372036000000000"),
+		float64(253402300800),
+		int64(-62135596801),
+	}
+	for _, value := range invalid {
+		claims := NewClaims()
+		claims.values["exp"] = value
+		if _, err := claims.GetExpiration(); err == nil {
+			test.Errorf("GetExpiration should have failed with %T %v", value, value)
+		}
+		if err := claims.ValidateTime(time.Now(), 0); err == nil || errors.Is(err, ErrTokenExpired) {
+			test.Errorf("ValidateTime should have failed with an invalid exp %T %v, but got %v", value, value, err)
+		}
+	}
+}
+
+func TestFarFutureNumericDates(test *testing.T) {
+	for _, name := range []string{"nbf", "exp", "iat"} {
+		claims := NewClaims()
+		if err := claims.Unmarshal([]byte(`{"` + name + `":9223372036000000000}`)); err != nil {
+			test.Fatalf("Failed to unmarshal %v: %v", name, err)
+		}
+		err := claims.ValidateTime(time.Now(), 0)
+		if err == nil {
+			test.Errorf("ValidateTime should have failed with a far future %v", name)
+		} else if errors.Is(err, ErrTokenExpired) {
+			test.Errorf("Expected a far future %v to be invalid, but got %v", name, err)
+		}
+	}
+
+	claims := NewClaims()
+	claims.Unmarshal([]byte(`{"exp":253402300799,"nbf":-62135596800}`))
+	if err := claims.ValidateTime(time.Now(), time.Hour); err != nil {
+		test.Errorf("Failed to validate the largest and smallest NumericDate: %v", err)
+	}
+}
+
+func TestAudiences(test *testing.T) {
+	claims := NewClaims()
+	if claims.HasAudience("api") {
+		test.Error("Expected claims without aud not to have audience api")
+	}
+	claims.SetAudiences([]string{"api", "billing"})
+	if auds, err := claims.GetAudiences(); err != nil {
+		test.Error(err.Error())
+	} else if len(auds) != 2 || auds[0] != "api" || auds[1] != "billing" {
+		test.Errorf("Expected aud to be [api billing], but got %v instead", auds)
+	}
+	if !claims.HasAudience("billing") {
+		test.Error("Expected claims to have audience billing")
+	}
+	if claims.HasAudience("admin") {
+		test.Error("Expected claims not to have audience admin")
+	}
+	if _, err := claims.GetAudience(); err == nil {
+		test.Error("GetAudience should have failed with multiple audiences")
+	}
+
+	empty := NewClaims()
+	empty.SetAudience("api")
+	empty.SetAudiences(nil)
+	if empty.Has("aud") {
+		test.Error("Expected SetAudiences with no audiences to delete aud")
+	}
+	if err := empty.Unmarshal([]byte(`{"aud":[]}`)); err != nil {
+		test.Fatalf("Unable to decode JSON claims: %v", err)
+	}
+	if _, err := empty.GetAudience(); err == nil || !strings.Contains(err.Error(), "aud is empty") {
+		test.Errorf("Expected GetAudience to report an empty aud, but got %v", err)
+	}
+
+	jsonBytes, err := claims.Marshal()
+	if err != nil {
+		test.Fatalf("Unable to marshal claims to JSON: %v", err)
+	}
+	decoded := NewClaims()
+	if err := decoded.Unmarshal(jsonBytes); err != nil {
+		test.Fatalf("Unable to decode JSON claims: %v", err)
+	}
+	if !decoded.HasAudience("api") || !decoded.HasAudience("billing") {
+		test.Errorf("Expected decoded claims to have both audiences: %v", string(jsonBytes))
+	}
+
+	if err := decoded.Unmarshal([]byte(`{"aud":["api"]}`)); err != nil {
+		test.Fatalf("Unable to decode JSON claims: %v", err)
+	}
+	if aud, err := decoded.GetAudience(); err != nil {
+		test.Error(err.Error())
+	} else if aud != "api" {
+		test.Errorf("Expected aud to be api, but got %v instead", aud)
+	}
+
+	if err := decoded.Unmarshal([]byte(`{"aud":"api"}`)); err != nil {
+		test.Fatalf("Unable to decode JSON claims: %v", err)
+	}
+	if auds, err := decoded.GetAudiences(); err != nil {
+		test.Error(err.Error())
+	} else if len(auds) != 1 || auds[0] != "api" {
+		test.Errorf("Expected aud to be [api], but got %v instead", auds)
+	}
+
+	if err := decoded.Unmarshal([]byte(`{"aud":["api",42]}`)); err != nil {
+		test.Fatalf("Unable to decode JSON claims: %v", err)
+	}
+	if _, err := decoded.GetAudiences(); err == nil {
+		test.Error("GetAudiences should have failed with a non string audience")
+	}
+}
+
+func TestSubject(test *testing.T) {
+	claims := NewClaims()
+	if _, err := claims.GetSubject(); err == nil {
+		test.Error("GetSubject should have failed with missing sub field.")
+	}
+	if _, err := claims.GetSubjectOrPrincipal(); err == nil {
+		test.Error("GetSubjectOrPrincipal should have failed with missing sub and prn fields.")
+	}
+
+	claims.SetPrincipal("legacy")
+	if _, err := claims.GetSubject(); err == nil {
+		test.Error("GetSubject should not fall back to prn.")
+	}
+	if v, err := claims.GetSubjectOrPrincipal(); err != nil {
+		test.Error(err.Error())
+	} else if v != "legacy" {
+		test.Errorf("Expected prn fallback to be legacy, but got %v instead", v)
+	}
+
+	claims.SetSubject("jrpalma")
+	if v, err := claims.GetSubject(); err != nil {
+		test.Error(err.Error())
+	} else if v != "jrpalma" {
+		test.Errorf("Expected sub to be jrpalma, but got %v instead", v)
+	}
+	if v, err := claims.GetSubjectOrPrincipal(); err != nil {
+		test.Error(err.Error())
+	} else if v != "jrpalma" {
+		test.Errorf("Expected sub to take precedence over prn, but got %v instead", v)
+	}
+
+	claims.Set("sub", 42)
+	if _, err := claims.GetSubject(); err == nil {
+		test.Error("GetSubject should have failed with invalid sub field.")
+	}
+	if _, err := claims.GetSubjectOrPrincipal(); err == nil {
+		test.Error("GetSubjectOrPrincipal should have failed with invalid sub field.")
+	}
+}
+
 func TestReservedClaims1(test *testing.T) {
 	claims := NewClaims()
 	claims.SetAudience("USA")
@@ -347,3 +566,47 @@ func TestNonExistingClaims2(test *testing.T) {
 		test.Errorf("Should fail")
 	}
 }
+
+func TestValidateTime(test *testing.T) {
+	now := time.Now()
+	claims := NewClaims()
+	if err := claims.ValidateTime(now, 0); err != nil {
+		test.Errorf("Expected claims without timestamps to be valid: %v", err)
+	}
+
+	claims.SetIssuedAt(now.Add(-time.Hour))
+	claims.SetNotBefore(now.Add(-time.Minute))
+	claims.SetExpiration(now.Add(time.Hour))
+	if err := claims.ValidateTime(now, 0); err != nil {
+		test.Errorf("Expected claims to be valid: %v", err)
+	}
+
+	claims.SetExpiration(now.Add(-time.Minute))
+	if err := claims.ValidateTime(now, 0); !errors.Is(err, ErrTokenExpired) {
+		test.Errorf("Expected ErrTokenExpired, but got %v instead", err)
+	}
+	if err := claims.ValidateTime(now, 2*time.Minute); err != nil {
+		test.Errorf("Expected leeway to allow expired claims: %v", err)
+	}
+	claims.SetExpiration(now.Add(time.Hour))
+
+	claims.SetNotBefore(now.Add(time.Minute))
+	if err := claims.ValidateTime(now, 0); !errors.Is(err, ErrTokenNotValidYet) {
+		test.Errorf("Expected ErrTokenNotValidYet, but got %v instead", err)
+	}
+	if err := claims.ValidateTime(now, 2*time.Minute); err != nil {
+		test.Errorf("Expected leeway to allow early claims: %v", err)
+	}
+	claims.SetNotBefore(now)
+
+	claims.SetIssuedAt(now.Add(time.Minute))
+	if err := claims.ValidateTime(now, 0); !errors.Is(err, ErrTokenUsedBeforeIssued) {
+		test.Errorf("Expected ErrTokenUsedBeforeIssued, but got %v instead", err)
+	}
+	claims.SetIssuedAt(now)
+
+	claims.Set("exp", "invalid date")
+	if err := claims.ValidateTime(now, 0); err == nil {
+		test.Error("ValidateTime should have failed with invalid exp")
+	}
+}
@@ -0,0 +1,113 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+)
+
+// SigningMethodECDSA implements the ECDSA family of signing methods.
+// Signing requires a *ecdsa.PrivateKey and verifying requires a
+// *ecdsa.PublicKey on Curve. Signatures use the fixed length R||S
+// encoding from RFC 7518 section 3.4 instead of ASN.1 DER.
+type SigningMethodECDSA struct {
+	Alg   string
+	Hash  crypto.Hash
+	Curve elliptic.Curve
+}
+
+// SigningMethodES256 signs and verifies using ECDSA P-256 SHA256.
+var SigningMethodES256 = &SigningMethodECDSA{Alg: "ES256", Hash: crypto.SHA256, Curve: elliptic.P256()}
+
+// SigningMethodES384 signs and verifies using ECDSA P-384 SHA384.
+var SigningMethodES384 = &SigningMethodECDSA{Alg: "ES384", Hash: crypto.SHA384, Curve: elliptic.P384()}
+
+// SigningMethodES512 signs and verifies using ECDSA P-521 SHA512.
+var SigningMethodES512 = &SigningMethodECDSA{Alg: "ES512", Hash: crypto.SHA512, Curve: elliptic.P521()}
+
+func init() {
+	RegisterSigningMethod(SigningMethodES256)
+	RegisterSigningMethod(SigningMethodES384)
+	RegisterSigningMethod(SigningMethodES512)
+}
+
+// Name returns the alg header value for the method.
+func (method *SigningMethodECDSA) Name() string {
+	return method.Alg
+}
+
+// Sign signs message with the *ecdsa.PrivateKey key.
+func (method *SigningMethodECDSA) Sign(message []byte, key interface{}) ([]byte, error) {
+	errMsg := "jwt: SigningMethodECDSA.Sign: %v"
+	privateKey, validType := key.(*ecdsa.PrivateKey)
+	if !validType {
+		return nil, fmt.Errorf(errMsg, fmt.Sprintf("Invalid key type %T", key))
+	}
+	if privateKey == nil || privateKey.D == nil {
+		return nil, fmt.Errorf(errMsg, "Invalid ECDSA private key")
+	}
+	if err := method.checkCurve(privateKey.Curve); err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+	digest, err := hashMessage(method.Hash, message)
+	if err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+	r, s, err := ecdsa.Sign(rand.Reader, privateKey, digest)
+	if err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+	size := method.keySize()
+	signature := append(padBytes(r.Bytes(), size), padBytes(s.Bytes(), size)...)
+	return signature, nil
+}
+
+// Verify checks signature against message with the *ecdsa.PublicKey key.
+func (method *SigningMethodECDSA) Verify(message []byte, signature []byte, key interface{}) error {
+	errMsg := "jwt: SigningMethodECDSA.Verify: %v"
+	var publicKey *ecdsa.PublicKey
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		publicKey = k
+	case *ecdsa.PrivateKey:
+		if k != nil {
+			publicKey = &k.PublicKey
+		}
+	default:
+		return fmt.Errorf(errMsg, fmt.Sprintf("Invalid key type %T", key))
+	}
+	if publicKey == nil || publicKey.X == nil || publicKey.Y == nil {
+		return fmt.Errorf(errMsg, "Invalid ECDSA public key")
+	}
+	if err := method.checkCurve(publicKey.Curve); err != nil {
+		return fmt.Errorf(errMsg, err)
+	}
+	size := method.keySize()
+	if len(signature) != 2*size {
+		return fmt.Errorf(errMsg, "Invalid signature")
+	}
+	digest, err := hashMessage(method.Hash, message)
+	if err != nil {
+		return fmt.Errorf(errMsg, err)
+	}
+	r := new(big.Int).SetBytes(signature[:size])
+	s := new(big.Int).SetBytes(signature[size:])
+	if !ecdsa.Verify(publicKey, digest, r, s) {
+		return fmt.Errorf(errMsg, "Invalid signature")
+	}
+	return nil
+}
+
+func (method *SigningMethodECDSA) checkCurve(curve elliptic.Curve) error {
+	if curve == nil || curve.Params().Name != method.Curve.Params().Name {
+		return fmt.Errorf("%v requires a key on curve %v", method.Alg, method.Curve.Params().Name)
+	}
+	return nil
+}
+
+func (method *SigningMethodECDSA) keySize() int {
+	return (method.Curve.Params().BitSize + 7) / 8
+}
@@ -0,0 +1,95 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestJWTECDSA(test *testing.T) {
+	curves := map[string]elliptic.Curve{
+		"ES256": elliptic.P256(),
+		"ES384": elliptic.P384(),
+		"ES512": elliptic.P521(),
+	}
+	signatureSizes := map[string]int{"ES256": 64, "ES384": 96, "ES512": 132}
+	for alg, curve := range curves {
+		privateKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			test.Fatalf("Failed to generate %v key: %v", alg, err)
+		}
+		token := NewJWT()
+		token.Header.Set("alg", alg)
+		token.Claims.SetIssuer("jwt")
+
+		compact, err := token.SignWithKey(privateKey)
+		if err != nil {
+			test.Errorf("Failed to sign %v token: %v", alg, err)
+			continue
+		}
+		tokens := strings.Split(compact, ".")
+		signature, _ := base64.RawURLEncoding.DecodeString(tokens[2])
+		if len(signature) != signatureSizes[alg] {
+			test.Errorf("Expected %v signature of %v bytes, but got %v instead", alg, signatureSizes[alg], len(signature))
+		}
+		verified := NewJWT()
+		if err := verified.VerifyWithKey(compact, &privateKey.PublicKey); err != nil {
+			test.Errorf("Failed to verify %v token: %v", alg, err)
+		}
+		if err := verified.VerifyWithKey(compact[:len(compact)-2], &privateKey.PublicKey); err == nil {
+			test.Errorf("Verify should have failed for %v with a truncated signature", alg)
+		}
+	}
+}
+
+func TestSigningMethodECDSACurveMismatch(test *testing.T) {
+	p256Key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	p384Key, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	message := []byte("message")
+
+	if _, err := SigningMethodES384.Sign(message, p256Key); err == nil {
+		test.Error("ES384 Sign should have failed with a P-256 key")
+	}
+	signature, err := SigningMethodES256.Sign(message, p256Key)
+	if err != nil {
+		test.Fatalf("Failed to sign message: %v", err)
+	}
+	if err := SigningMethodES256.Verify(message, signature, &p384Key.PublicKey); err == nil {
+		test.Error("ES256 Verify should have failed with a P-384 key")
+	}
+	if err := SigningMethodES384.Verify(message, signature, &p256Key.PublicKey); err == nil {
+		test.Error("ES384 Verify should have failed with a P-256 key")
+	}
+	if err := SigningMethodES256.Verify(message, signature, "secret"); err == nil {
+		test.Error("ES256 Verify should have failed with a secret")
+	}
+}
+
+func TestSigningMethodECDSAInvalidKeys(test *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	message := []byte("message")
+	signature, _ := SigningMethodES256.Sign(message, key)
+	invalid := []interface{}{
+		&ecdsa.PublicKey{Curve: elliptic.P256()},
+		&ecdsa.PublicKey{Curve: elliptic.P256(), X: key.X},
+		(*ecdsa.PublicKey)(nil),
+		(*ecdsa.PrivateKey)(nil),
+	}
+	for _, value := range invalid {
+		if err := SigningMethodES256.Verify(message, signature, value); err == nil {
+			test.Errorf("ES256 Verify should have failed with %#v", value)
+		}
+	}
+	token := NewJWT()
+	token.Header.Set("alg", "ES256")
+	compact, _ := token.SignWithKey(key)
+	if err := NewJWT().VerifyWithKey(compact, &ecdsa.PublicKey{Curve: elliptic.P256()}); err == nil {
+		test.Error("VerifyWithKey should have failed without X and Y")
+	}
+	if _, err := SigningMethodES256.Sign(message, &ecdsa.PrivateKey{PublicKey: key.PublicKey}); err == nil {
+		test.Error("ES256 Sign should have failed without D")
+	}
+}
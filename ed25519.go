@@ -0,0 +1,73 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"fmt"
+)
+
+// SigningMethodEd25519 implements the EdDSA signing method from RFC 8037
+// using Ed25519. Signing requires an ed25519.PrivateKey and verifying
+// requires an ed25519.PublicKey.
+type SigningMethodEd25519 struct{}
+
+// SigningMethodEdDSA signs and verifies using Ed25519.
+var SigningMethodEdDSA = &SigningMethodEd25519{}
+
+func init() {
+	RegisterSigningMethod(SigningMethodEdDSA)
+}
+
+// Name returns the alg header value for the method.
+func (method *SigningMethodEd25519) Name() string {
+	return "EdDSA"
+}
+
+// Sign signs message with the ed25519.PrivateKey key.
+func (method *SigningMethodEd25519) Sign(message []byte, key interface{}) ([]byte, error) {
+	errMsg := "jwt: SigningMethodEd25519.Sign: %v"
+	var privateKey ed25519.PrivateKey
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		privateKey = k
+	case *ed25519.PrivateKey:
+		if k == nil {
+			return nil, fmt.Errorf(errMsg, "Key is nil")
+		}
+		privateKey = *k
+	default:
+		return nil, fmt.Errorf(errMsg, fmt.Sprintf("Invalid key type %T", key))
+	}
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf(errMsg, "Invalid Ed25519 private key size")
+	}
+	return ed25519.Sign(privateKey, message), nil
+}
+
+// Verify checks signature against message with the ed25519.PublicKey key.
+func (method *SigningMethodEd25519) Verify(message []byte, signature []byte, key interface{}) error {
+	errMsg := "jwt: SigningMethodEd25519.Verify: %v"
+	var publicKey ed25519.PublicKey
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		publicKey = k
+	case *ed25519.PublicKey:
+		if k == nil {
+			return fmt.Errorf(errMsg, "Key is nil")
+		}
+		publicKey = *k
+	case ed25519.PrivateKey:
+		if len(k) != ed25519.PrivateKeySize {
+			return fmt.Errorf(errMsg, "Invalid Ed25519 private key size")
+		}
+		publicKey, _ = k.Public().(ed25519.PublicKey)
+	default:
+		return fmt.Errorf(errMsg, fmt.Sprintf("Invalid key type %T", key))
+	}
+	if len(publicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf(errMsg, "Invalid Ed25519 public key size")
+	}
+	if !ed25519.Verify(publicKey, message, signature) {
+		return fmt.Errorf(errMsg, "Invalid signature")
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+)
+
+func TestJWTEdDSA(test *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		test.Fatalf("Failed to generate Ed25519 key: %v", err)
+	}
+	token := NewJWT()
+	token.Header.Set("alg", "EdDSA")
+	token.Claims.SetIssuer("jwt")
+
+	if _, err := token.Header.Marshal(); err != nil {
+		test.Errorf("Failed to marshal EdDSA header: %v", err)
+	}
+	compact, err := token.SignWithKey(privateKey)
+	if err != nil {
+		test.Fatalf("Failed to sign EdDSA token: %v", err)
+	}
+	verified := NewJWT()
+	if err := verified.VerifyWithKey(compact, publicKey); err != nil {
+		test.Errorf("Failed to verify EdDSA token: %v", err)
+	}
+	if a, _ := verified.Header.GetString("alg"); a != "EdDSA" {
+		test.Errorf("Expected alg to be EdDSA, but got %v instead", a)
+	}
+
+	otherPublicKey, _, _ := ed25519.GenerateKey(rand.Reader)
+	if err := verified.VerifyWithKey(compact, otherPublicKey); err == nil {
+		test.Error("Verify should have failed with a different public key")
+	}
+	if err := verified.VerifyWithKey(compact, "secret"); err == nil {
+		test.Error("Verify should have failed with a secret")
+	}
+	if _, err := token.SignWithKey(publicKey); err == nil {
+		test.Error("Sign should have failed with a public key")
+	}
+
+	//Malformed keys must fail instead of panicking
+	if err := verified.VerifyWithKey(compact, ed25519.PrivateKey{1, 2, 3}); err == nil {
+		test.Error("Verify should have failed with a short private key")
+	}
+	if err := SigningMethodEdDSA.Verify([]byte("message"), nil, (*ed25519.PublicKey)(nil)); err == nil {
+		test.Error("Verify should have failed with a nil public key")
+	}
+	if _, err := SigningMethodEdDSA.Sign([]byte("message"), (*ed25519.PrivateKey)(nil)); err == nil {
+		test.Error("Sign should have failed with a nil private key")
+	}
+}
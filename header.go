@@ -1,6 +1,10 @@
 package jwt
 
 import (
+	"crypto"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -144,6 +148,113 @@ func (header *Header) GetTime(name string) (time.Time, error) {
 	return timeVal, nil
 }
 
+// SetKeyThumbprint sets the kid value in the Header to the base 64 URL
+// encoded RFC 7638 SHA256 thumbprint of key. The key can be a *JWK or any
+// key supported by NewJWK.
+func (header *Header) SetKeyThumbprint(key interface{}) error {
+	errMsg := "jwt: Header.SetKeyThumbprint: %v"
+	jwk, isJWK := key.(*JWK)
+	if !isJWK {
+		var err error
+		if jwk, err = NewJWK(key); err != nil {
+			return fmt.Errorf(errMsg, err)
+		}
+	}
+	kid, err := jwk.ThumbprintString(crypto.SHA256)
+	if err != nil {
+		return fmt.Errorf(errMsg, err)
+	}
+	header.values["kid"] = kid
+	return nil
+}
+
+// SetCertificateChain sets the x5c value in the Header to the base 64 DER
+// encoding of certs. The first certificate must hold the key that signs
+// the token and each following certificate must certify the previous one.
+func (header *Header) SetCertificateChain(certs []*x509.Certificate) {
+	chain := make([]interface{}, len(certs))
+	for i, cert := range certs {
+		chain[i] = base64.StdEncoding.EncodeToString(cert.Raw)
+	}
+	header.values["x5c"] = chain
+}
+
+// GetCertificateChain gets the certificates in the x5c value of the Header.
+// The certificates are parsed but not verified.
+func (header *Header) GetCertificateChain() ([]*x509.Certificate, error) {
+	errMsg := "jwt: Header.GetCertificateChain: %v"
+	value, exists := header.values["x5c"]
+	if !exists {
+		return nil, fmt.Errorf(errMsg, "No such value x5c")
+	}
+	var encoded []string
+	switch v := value.(type) {
+	case []string:
+		encoded = v
+	case []interface{}:
+		for _, item := range v {
+			str, validType := item.(string)
+			if !validType {
+				return nil, fmt.Errorf(errMsg, "x5c is not a string array value")
+			}
+			encoded = append(encoded, str)
+		}
+	default:
+		return nil, fmt.Errorf(errMsg, "x5c is not a string array value")
+	}
+	if len(encoded) == 0 {
+		return nil, fmt.Errorf(errMsg, "x5c is empty")
+	}
+	certs := make([]*x509.Certificate, len(encoded))
+	for i, str := range encoded {
+		der, err := base64.StdEncoding.DecodeString(str)
+		if err != nil {
+			return nil, fmt.Errorf(errMsg, err)
+		}
+		if certs[i], err = x509.ParseCertificate(der); err != nil {
+			return nil, fmt.Errorf(errMsg, err)
+		}
+	}
+	return certs, nil
+}
+
+// SetCertificateThumbprint sets the x5t value in the Header to the base 64
+// URL encoded SHA1 thumbprint of cert.
+func (header *Header) SetCertificateThumbprint(cert *x509.Certificate) {
+	sum := sha1.Sum(cert.Raw)
+	header.Set("x5t", sum[:])
+}
+
+// GetCertificateThumbprint gets the SHA1 thumbprint in the x5t value of the
+// Header.
+func (header *Header) GetCertificateThumbprint() ([]byte, error) {
+	return header.GetBytes("x5t")
+}
+
+// SetCertificateThumbprintS256 sets the x5t#S256 value in the Header to the
+// base 64 URL encoded SHA256 thumbprint of cert.
+func (header *Header) SetCertificateThumbprintS256(cert *x509.Certificate) {
+	sum := sha256.Sum256(cert.Raw)
+	header.Set("x5t#S256", sum[:])
+}
+
+// GetCertificateThumbprintS256 gets the SHA256 thumbprint in the x5t#S256
+// value of the Header.
+func (header *Header) GetCertificateThumbprintS256() ([]byte, error) {
+	return header.GetBytes("x5t#S256")
+}
+
+// SetCertificateURL sets the x5u value in the Header.
+func (header *Header) SetCertificateURL(url string) {
+	header.values["x5u"] = url
+}
+
+// GetCertificateURL gets the x5u value in the Header. The certificates at
+// the URL are not fetched.
+func (header *Header) GetCertificateURL() (string, error) {
+	return header.GetString("x5u")
+}
+
 // Keys gets the names of all the values in the Header.
 func (header *Header) Keys() []string {
 	keys := make([]string, 0, len(header.values))
@@ -173,7 +284,7 @@ func (header *Header) Marshal() ([]byte, error) {
 	if err != nil {
 		return nil, fmt.Errorf(errMsg, err)
 	}
-	if a != "HS256" {
+	if _, exists := GetSigningMethod(a); !exists {
 		return nil, fmt.Errorf(errMsg, "Invalid alg "+a)
 	}
 	bytes, err := json.Marshal(header.values)
@@ -187,9 +298,16 @@ func (header *Header) Marshal() ([]byte, error) {
 // Unmarshal decodes the header values into a Header object.
 func (header *Header) Unmarshal(bytes []byte) error {
 	errMsg := "jwt: Header.Marshal: %v"
-	err := json.Unmarshal(bytes, &header.values)
+	var values map[string]interface{}
+	err := json.Unmarshal(bytes, &values)
 	if err != nil {
 		return fmt.Errorf(errMsg, err)
 	}
+	if values == nil {
+		return fmt.Errorf(errMsg, "Header is not a JSON object")
+	}
+	for key, value := range values {
+		header.values[key] = value
+	}
 	return nil
 }
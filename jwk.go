@@ -0,0 +1,437 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// JWK represents a JSON Web Key from RFC 7517. Key holds the Go crypto key
+// and is one of []byte for oct keys, *rsa.PublicKey or *rsa.PrivateKey for
+// RSA keys, *ecdsa.PublicKey or *ecdsa.PrivateKey for EC keys, and
+// ed25519.PublicKey or ed25519.PrivateKey for OKP keys. A *JWK can be passed
+// to SignWithKey, VerifyWithKey, and StaticKey in place of the key it holds.
+type JWK struct {
+	Key       interface{}
+	KeyID     string
+	Use       string
+	Algorithm string
+	KeyOps    []string
+}
+
+type jwkJSON struct {
+	KeyType   string   `json:"kty"`
+	KeyID     string   `json:"kid,omitempty"`
+	Use       string   `json:"use,omitempty"`
+	Algorithm string   `json:"alg,omitempty"`
+	KeyOps    []string `json:"key_ops,omitempty"`
+	Curve     string   `json:"crv,omitempty"`
+	K         string   `json:"k,omitempty"`
+	N         string   `json:"n,omitempty"`
+	E         string   `json:"e,omitempty"`
+	X         string   `json:"x,omitempty"`
+	Y         string   `json:"y,omitempty"`
+	D         string   `json:"d,omitempty"`
+	P         string   `json:"p,omitempty"`
+	Q         string   `json:"q,omitempty"`
+	DP        string   `json:"dp,omitempty"`
+	DQ        string   `json:"dq,omitempty"`
+	QI        string   `json:"qi,omitempty"`
+}
+
+// NewJWK creates a new JWK holding key. It returns an error if the key type
+// is not supported.
+func NewJWK(key interface{}) (*JWK, error) {
+	errMsg := "jwt: NewJWK: %v"
+	jwk := &JWK{Key: key}
+	if _, err := jwk.keyType(); err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+	return jwk, nil
+}
+
+// KeyType returns the kty of the JWK. It is one of "oct", "RSA", "EC", or
+// "OKP", or an empty string if the key type is not supported.
+func (jwk *JWK) KeyType() string {
+	kty, _ := jwk.keyType()
+	return kty
+}
+
+func (jwk *JWK) keyType() (string, error) {
+	switch jwk.Key.(type) {
+	case []byte:
+		return "oct", nil
+	case *rsa.PublicKey, *rsa.PrivateKey:
+		return "RSA", nil
+	case *ecdsa.PublicKey, *ecdsa.PrivateKey:
+		return "EC", nil
+	case ed25519.PublicKey, ed25519.PrivateKey:
+		return "OKP", nil
+	default:
+		return "", fmt.Errorf("Unsupported key type %T", jwk.Key)
+	}
+}
+
+// IsPublic returns true if the JWK holds a public key.
+func (jwk *JWK) IsPublic() bool {
+	switch jwk.Key.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey:
+		return true
+	default:
+		return false
+	}
+}
+
+// Public returns a JWK holding the public key of an RSA, EC, or OKP private
+// key. The kid, use, alg, and key_ops of the JWK are kept. Public keys are
+// returned as is and oct keys return an error.
+func (jwk *JWK) Public() (*JWK, error) {
+	errMsg := "jwt: JWK.Public: %v"
+	public := &JWK{KeyID: jwk.KeyID, Use: jwk.Use, Algorithm: jwk.Algorithm, KeyOps: jwk.KeyOps}
+	switch k := jwk.Key.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey:
+		public.Key = k
+	case *rsa.PrivateKey:
+		if k == nil {
+			return nil, fmt.Errorf(errMsg, "Key is nil")
+		}
+		public.Key = &k.PublicKey
+	case *ecdsa.PrivateKey:
+		if k == nil {
+			return nil, fmt.Errorf(errMsg, "Key is nil")
+		}
+		public.Key = &k.PublicKey
+	case ed25519.PrivateKey:
+		if len(k) != ed25519.PrivateKeySize {
+			return nil, fmt.Errorf(errMsg, "Invalid Ed25519 private key size")
+		}
+		public.Key = k.Public()
+	default:
+		return nil, fmt.Errorf(errMsg, fmt.Sprintf("No public key for %T", jwk.Key))
+	}
+	return public, nil
+}
+
+// MarshalJSON encodes the JWK into JSON.
+func (jwk *JWK) MarshalJSON() ([]byte, error) {
+	errMsg := "jwt: JWK.MarshalJSON: %v"
+	raw := jwkJSON{KeyID: jwk.KeyID, Use: jwk.Use, Algorithm: jwk.Algorithm, KeyOps: jwk.KeyOps}
+	switch k := jwk.Key.(type) {
+	case []byte:
+		raw.KeyType = "oct"
+		raw.K = encodeBytes(k)
+	case *rsa.PublicKey:
+		raw.KeyType = "RSA"
+		if err := setRSAPublicJSON(&raw, k); err != nil {
+			return nil, fmt.Errorf(errMsg, err)
+		}
+	case *rsa.PrivateKey:
+		if k == nil || k.D == nil {
+			return nil, fmt.Errorf(errMsg, "Invalid RSA private key")
+		}
+		if len(k.Primes) != 2 || k.Primes[0] == nil || k.Primes[1] == nil {
+			return nil, fmt.Errorf(errMsg, "RSA keys must have exactly two primes")
+		}
+		raw.KeyType = "RSA"
+		if err := setRSAPublicJSON(&raw, &k.PublicKey); err != nil {
+			return nil, fmt.Errorf(errMsg, err)
+		}
+		k.Precompute()
+		raw.D = encodeBytes(k.D.Bytes())
+		raw.P = encodeBytes(k.Primes[0].Bytes())
+		raw.Q = encodeBytes(k.Primes[1].Bytes())
+		raw.DP = encodeBytes(k.Precomputed.Dp.Bytes())
+		raw.DQ = encodeBytes(k.Precomputed.Dq.Bytes())
+		raw.QI = encodeBytes(k.Precomputed.Qinv.Bytes())
+	case *ecdsa.PublicKey:
+		raw.KeyType = "EC"
+		if err := setECPublicJSON(&raw, k); err != nil {
+			return nil, fmt.Errorf(errMsg, err)
+		}
+	case *ecdsa.PrivateKey:
+		if k == nil || k.D == nil {
+			return nil, fmt.Errorf(errMsg, "Invalid EC private key")
+		}
+		raw.KeyType = "EC"
+		if err := setECPublicJSON(&raw, &k.PublicKey); err != nil {
+			return nil, fmt.Errorf(errMsg, err)
+		}
+		raw.D = encodeBytes(padBytes(k.D.Bytes(), curveSize(k.Curve)))
+	case ed25519.PublicKey:
+		if len(k) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf(errMsg, "Invalid Ed25519 public key size")
+		}
+		raw.KeyType = "OKP"
+		raw.Curve = "Ed25519"
+		raw.X = encodeBytes(k)
+	case ed25519.PrivateKey:
+		if len(k) != ed25519.PrivateKeySize {
+			return nil, fmt.Errorf(errMsg, "Invalid Ed25519 private key size")
+		}
+		raw.KeyType = "OKP"
+		raw.Curve = "Ed25519"
+		raw.X = encodeBytes(k.Public().(ed25519.PublicKey))
+		raw.D = encodeBytes(k.Seed())
+	default:
+		return nil, fmt.Errorf(errMsg, fmt.Sprintf("Unsupported key type %T", jwk.Key))
+	}
+	bytes, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+	return bytes, nil
+}
+
+// UnmarshalJSON decodes a JWK from JSON. Private key members are decoded
+// into private keys.
+func (jwk *JWK) UnmarshalJSON(bytes []byte) error {
+	errMsg := "jwt: JWK.UnmarshalJSON: %v"
+	var raw jwkJSON
+	if err := json.Unmarshal(bytes, &raw); err != nil {
+		return fmt.Errorf(errMsg, err)
+	}
+	var key interface{}
+	var err error
+	switch raw.KeyType {
+	case "oct":
+		key, err = decodeBytes("k", raw.K)
+	case "RSA":
+		key, err = rsaKeyFromJSON(&raw)
+	case "EC":
+		key, err = ecKeyFromJSON(&raw)
+	case "OKP":
+		key, err = okpKeyFromJSON(&raw)
+	default:
+		err = fmt.Errorf("Unsupported kty %v", raw.KeyType)
+	}
+	if err != nil {
+		return fmt.Errorf(errMsg, err)
+	}
+	jwk.Key = key
+	jwk.KeyID = raw.KeyID
+	jwk.Use = raw.Use
+	jwk.Algorithm = raw.Algorithm
+	jwk.KeyOps = raw.KeyOps
+	return nil
+}
+
+// signingKey returns the key held by the JWK after checking that the JWK
+// can be used with alg for op. The op parameter is "sign" or "verify".
+func (jwk *JWK) signingKey(alg string, op string) (interface{}, error) {
+	if jwk.Algorithm != "" && jwk.Algorithm != alg {
+		return nil, fmt.Errorf("JWK alg %v does not match alg %v", jwk.Algorithm, alg)
+	}
+	if jwk.Use != "" && jwk.Use != "sig" {
+		return nil, fmt.Errorf("JWK use %v is not sig", jwk.Use)
+	}
+	if len(jwk.KeyOps) > 0 {
+		allowed := false
+		for _, keyOp := range jwk.KeyOps {
+			allowed = allowed || keyOp == op
+		}
+		if !allowed {
+			return nil, fmt.Errorf("JWK key_ops does not allow %v", op)
+		}
+	}
+	return jwk.Key, nil
+}
+
+// unwrapKey returns the key held by key if it is a *JWK, or key otherwise.
+func unwrapKey(key interface{}, alg string, op string) (interface{}, error) {
+	if jwk, isJWK := key.(*JWK); isJWK {
+		return jwk.signingKey(alg, op)
+	}
+	return key, nil
+}
+
+func setRSAPublicJSON(raw *jwkJSON, key *rsa.PublicKey) error {
+	if key == nil || key.N == nil {
+		return fmt.Errorf("Invalid RSA public key")
+	}
+	raw.N = encodeBytes(key.N.Bytes())
+	raw.E = encodeBytes(big.NewInt(int64(key.E)).Bytes())
+	return nil
+}
+
+func setECPublicJSON(raw *jwkJSON, key *ecdsa.PublicKey) error {
+	if key == nil || key.X == nil || key.Y == nil {
+		return fmt.Errorf("Invalid EC public key")
+	}
+	name, err := curveName(key.Curve)
+	if err != nil {
+		return err
+	}
+	size := curveSize(key.Curve)
+	raw.Curve = name
+	raw.X = encodeBytes(padBytes(key.X.Bytes(), size))
+	raw.Y = encodeBytes(padBytes(key.Y.Bytes(), size))
+	return nil
+}
+
+func rsaKeyFromJSON(raw *jwkJSON) (interface{}, error) {
+	n, err := decodeBigInt("n", raw.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := decodeBigInt("e", raw.E)
+	if err != nil {
+		return nil, err
+	}
+	if !e.IsInt64() || e.Int64() > int64(^uint32(0)>>1) {
+		return nil, fmt.Errorf("Invalid e value")
+	}
+	publicKey := &rsa.PublicKey{N: n, E: int(e.Int64())}
+	if raw.D == "" {
+		return publicKey, nil
+	}
+	d, err := decodeBigInt("d", raw.D)
+	if err != nil {
+		return nil, err
+	}
+	p, err := decodeBigInt("p", raw.P)
+	if err != nil {
+		return nil, err
+	}
+	q, err := decodeBigInt("q", raw.Q)
+	if err != nil {
+		return nil, err
+	}
+	privateKey := &rsa.PrivateKey{PublicKey: *publicKey, D: d, Primes: []*big.Int{p, q}}
+	if err := privateKey.Validate(); err != nil {
+		return nil, err
+	}
+	privateKey.Precompute()
+	return privateKey, nil
+}
+
+func ecKeyFromJSON(raw *jwkJSON) (interface{}, error) {
+	var curve elliptic.Curve
+	switch raw.Curve {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("Unsupported crv %v", raw.Curve)
+	}
+	size := curveSize(curve)
+	x, err := decodeFixedBigInt("x", raw.X, size)
+	if err != nil {
+		return nil, err
+	}
+	y, err := decodeFixedBigInt("y", raw.Y, size)
+	if err != nil {
+		return nil, err
+	}
+	if !curve.IsOnCurve(x, y) {
+		return nil, fmt.Errorf("Point is not on curve %v", raw.Curve)
+	}
+	publicKey := &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+	if raw.D == "" {
+		return publicKey, nil
+	}
+	d, err := decodeFixedBigInt("d", raw.D, size)
+	if err != nil {
+		return nil, err
+	}
+	privateKey := &ecdsa.PrivateKey{PublicKey: *publicKey, D: d}
+	dx, dy := curve.ScalarBaseMult(padBytes(d.Bytes(), size))
+	if dx.Cmp(x) != 0 || dy.Cmp(y) != 0 {
+		return nil, fmt.Errorf("d does not match x and y")
+	}
+	return privateKey, nil
+}
+
+func okpKeyFromJSON(raw *jwkJSON) (interface{}, error) {
+	if raw.Curve != "Ed25519" {
+		return nil, fmt.Errorf("Unsupported crv %v", raw.Curve)
+	}
+	x, err := decodeBytes("x", raw.X)
+	if err != nil {
+		return nil, err
+	}
+	if len(x) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("Invalid x value")
+	}
+	if raw.D == "" {
+		return ed25519.PublicKey(x), nil
+	}
+	seed, err := decodeBytes("d", raw.D)
+	if err != nil {
+		return nil, err
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("Invalid d value")
+	}
+	privateKey := ed25519.NewKeyFromSeed(seed)
+	if string(privateKey.Public().(ed25519.PublicKey)) != string(x) {
+		return nil, fmt.Errorf("d does not match x")
+	}
+	return privateKey, nil
+}
+
+func curveName(curve elliptic.Curve) (string, error) {
+	if curve == nil {
+		return "", fmt.Errorf("No curve")
+	}
+	switch curve.Params().Name {
+	case "P-256", "P-384", "P-521":
+		return curve.Params().Name, nil
+	default:
+		return "", fmt.Errorf("Unsupported curve %v", curve.Params().Name)
+	}
+}
+
+func curveSize(curve elliptic.Curve) int {
+	return (curve.Params().BitSize + 7) / 8
+}
+
+func padBytes(bytes []byte, size int) []byte {
+	if len(bytes) >= size {
+		return bytes
+	}
+	padded := make([]byte, size)
+	copy(padded[size-len(bytes):], bytes)
+	return padded
+}
+
+func encodeBytes(bytes []byte) string {
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
+
+func decodeBytes(name string, value string) ([]byte, error) {
+	if value == "" {
+		return nil, fmt.Errorf("No such value %v", name)
+	}
+	bytes, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid %v value", name)
+	}
+	return bytes, nil
+}
+
+func decodeBigInt(name string, value string) (*big.Int, error) {
+	bytes, err := decodeBytes(name, value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(bytes), nil
+}
+
+func decodeFixedBigInt(name string, value string, size int) (*big.Int, error) {
+	bytes, err := decodeBytes(name, value)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes) != size {
+		return nil, fmt.Errorf("Invalid %v value", name)
+	}
+	return new(big.Int).SetBytes(bytes), nil
+}
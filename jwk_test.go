@@ -0,0 +1,197 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"testing"
+)
+
+func testJWKKeys(test *testing.T) map[string]interface{} {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		test.Fatalf("Failed to generate EC key: %v", err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		test.Fatalf("Failed to generate Ed25519 key: %v", err)
+	}
+	return map[string]interface{}{
+		"HS256": []byte("secret"),
+		"RS256": getTestRSAKey(test),
+		"ES384": ecKey,
+		"EdDSA": edKey,
+	}
+}
+
+func TestJWKRoundTrip(test *testing.T) {
+	kinds := map[string]string{"HS256": "oct", "RS256": "RSA", "ES384": "EC", "EdDSA": "OKP"}
+	for alg, key := range testJWKKeys(test) {
+		jwk, err := NewJWK(key)
+		if err != nil {
+			test.Fatalf("Failed to create %v JWK: %v", alg, err)
+		}
+		jwk.KeyID = alg + "-key"
+		jwk.Use = "sig"
+		jwk.Algorithm = alg
+		jwk.KeyOps = []string{"sign", "verify"}
+		if jwk.KeyType() != kinds[alg] {
+			test.Errorf("Expected %v kty to be %v, but got %v instead", alg, kinds[alg], jwk.KeyType())
+		}
+
+		jsonBytes, err := json.Marshal(jwk)
+		if err != nil {
+			test.Fatalf("Failed to marshal %v JWK: %v", alg, err)
+		}
+		decoded := &JWK{}
+		if err := json.Unmarshal(jsonBytes, decoded); err != nil {
+			test.Fatalf("Failed to unmarshal %v JWK: %v", alg, err)
+		}
+		if decoded.KeyID != jwk.KeyID || decoded.Use != "sig" || decoded.Algorithm != alg || len(decoded.KeyOps) != 2 {
+			test.Errorf("Expected %v JWK parameters to survive a round trip: %v", alg, string(jsonBytes))
+		}
+
+		token := NewJWT()
+		token.Header.Set("alg", alg)
+		compact, err := token.SignWithKey(decoded)
+		if err != nil {
+			test.Errorf("Failed to sign %v token with JWK: %v", alg, err)
+			continue
+		}
+		verifyKey := jwk
+		if alg != "HS256" {
+			if verifyKey, err = jwk.Public(); err != nil {
+				test.Fatalf("Failed to get %v public JWK: %v", alg, err)
+			}
+			if !verifyKey.IsPublic() {
+				test.Errorf("Expected %v public JWK to be public", alg)
+			}
+			publicBytes, _ := json.Marshal(verifyKey)
+			verifyKey = &JWK{}
+			if err := json.Unmarshal(publicBytes, verifyKey); err != nil {
+				test.Fatalf("Failed to unmarshal %v public JWK: %v", alg, err)
+			}
+		}
+		if _, err := Parse(compact, StaticKey(verifyKey)); err != nil {
+			test.Errorf("Failed to verify %v token with JWK: %v", alg, err)
+		}
+	}
+}
+
+func TestJWKRestrictions(test *testing.T) {
+	jwk, _ := NewJWK([]byte("secret"))
+	token := NewJWT()
+	compact, _ := token.Sign("secret")
+
+	jwk.Algorithm = "HS512"
+	if _, err := token.SignWithKey(jwk); err == nil {
+		test.Error("Sign should have failed with a JWK for a different alg")
+	}
+	if _, err := Parse(compact, StaticKey(jwk)); err == nil {
+		test.Error("Parse should have failed with a JWK for a different alg")
+	}
+	jwk.Algorithm = ""
+
+	jwk.Use = "enc"
+	if _, err := Parse(compact, StaticKey(jwk)); err == nil {
+		test.Error("Parse should have failed with an encryption JWK")
+	}
+	jwk.Use = ""
+
+	jwk.KeyOps = []string{"verify"}
+	if _, err := token.SignWithKey(jwk); err == nil {
+		test.Error("Sign should have failed with a verify only JWK")
+	}
+	if _, err := Parse(compact, StaticKey(jwk)); err != nil {
+		test.Errorf("Failed to verify token with a verify only JWK: %v", err)
+	}
+}
+
+func TestJWKUnmarshal(test *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ecJWK, _ := NewJWK(ecKey)
+	otherJWK, _ := NewJWK(otherKey)
+	ecJSON, _ := json.Marshal(ecJWK)
+	otherJSON, _ := json.Marshal(otherJWK)
+	jwk := &JWK{}
+	if err := json.Unmarshal(ecJSON, jwk); err != nil {
+		test.Fatalf("Failed to unmarshal EC JWK: %v", err)
+	}
+	if _, isPrivate := jwk.Key.(*ecdsa.PrivateKey); !isPrivate {
+		test.Errorf("Expected a *ecdsa.PrivateKey, but got %T instead", jwk.Key)
+	}
+
+	var raw, other map[string]interface{}
+	json.Unmarshal(ecJSON, &raw)
+	json.Unmarshal(otherJSON, &other)
+	raw["d"] = other["d"]
+	mismatched, _ := json.Marshal(raw)
+	if err := json.Unmarshal(mismatched, &JWK{}); err == nil {
+		test.Error("Unmarshal should have failed with a d that does not match x and y")
+	}
+
+	invalid := []string{
+		`{"kty":"unknown"}`,
+		`{"kty":"oct"}`,
+		`{"kty":"RSA","n":"AQAB"}`,
+		`{"kty":"EC","crv":"P-192","x":"AA","y":"AA"}`,
+		`{"kty":"EC","crv":"P-256","x":"` + raw["x"].(string) + `","y":"` + raw["x"].(string) + `"}`,
+		`{"kty":"OKP","crv":"X25519","x":"AA"}`,
+		`{"kty":"OKP","crv":"Ed25519","x":"AA"}`,
+		`not JSON`,
+	}
+	for _, value := range invalid {
+		if err := json.Unmarshal([]byte(value), &JWK{}); err == nil {
+			test.Errorf("Unmarshal should have failed with %v", value)
+		}
+	}
+
+	if _, err := NewJWK("secret"); err == nil {
+		test.Error("NewJWK should have failed with a string key")
+	}
+	octJWK, _ := NewJWK([]byte("secret"))
+	if _, err := octJWK.Public(); err == nil {
+		test.Error("Public should have failed with an oct key")
+	}
+}
+
+func TestJWKMarshalInvalidKeys(test *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	invalid := []interface{}{
+		&rsa.PublicKey{},
+		(*rsa.PublicKey)(nil),
+		&rsa.PrivateKey{},
+		(*rsa.PrivateKey)(nil),
+		&ecdsa.PublicKey{Curve: elliptic.P256()},
+		&ecdsa.PublicKey{Curve: elliptic.P256(), X: ecKey.X},
+		(*ecdsa.PublicKey)(nil),
+		(*ecdsa.PrivateKey)(nil),
+		ed25519.PublicKey{1, 2, 3},
+		ed25519.PrivateKey{1, 2, 3},
+	}
+	for _, key := range invalid {
+		jwk := &JWK{Key: key}
+		if _, err := jwk.MarshalJSON(); err == nil {
+			test.Errorf("MarshalJSON should have failed with %#v", key)
+		}
+		if _, err := jwk.Thumbprint(0); err == nil {
+			test.Errorf("Thumbprint should have failed with %#v", key)
+		}
+		if err := NewHeader().SetKeyThumbprint(key); err == nil {
+			test.Errorf("SetKeyThumbprint should have failed with %#v", key)
+		}
+	}
+
+	//The thumbprint only needs the public key
+	noD := &JWK{Key: &ecdsa.PrivateKey{PublicKey: ecKey.PublicKey}}
+	if _, err := noD.MarshalJSON(); err == nil {
+		test.Error("MarshalJSON should have failed with an EC private key without D")
+	}
+	if _, err := noD.Thumbprint(0); err != nil {
+		test.Errorf("Failed to compute the thumbprint of an EC private key without D: %v", err)
+	}
+}
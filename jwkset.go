@@ -0,0 +1,128 @@
+package jwt
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// JWKSet represents a JSON Web Key Set from RFC 7517 section 5. A *JWKSet
+// is a KeyProvider that selects the key matching the kid and alg headers
+// of the token being verified.
+type JWKSet struct {
+	Keys []*JWK
+}
+
+type jwkSetJSON struct {
+	Keys []json.RawMessage `json:"keys"`
+}
+
+// ParseJWKSet decodes a JWK Set JSON document. Keys that cannot be decoded,
+// such as keys with a kty or crv that is not supported, are skipped as
+// required by RFC 7517 section 5.
+func ParseJWKSet(bytes []byte) (*JWKSet, error) {
+	errMsg := "jwt: ParseJWKSet: %v"
+	set := &JWKSet{}
+	if err := set.UnmarshalJSON(bytes); err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+	return set, nil
+}
+
+// MarshalJSON encodes the JWKSet into JSON.
+func (set *JWKSet) MarshalJSON() ([]byte, error) {
+	keys := set.Keys
+	if keys == nil {
+		keys = []*JWK{}
+	}
+	return json.Marshal(struct {
+		Keys []*JWK `json:"keys"`
+	}{Keys: keys})
+}
+
+// UnmarshalJSON decodes a JWKSet from JSON. Keys that cannot be decoded are
+// skipped.
+func (set *JWKSet) UnmarshalJSON(bytes []byte) error {
+	var raw jwkSetJSON
+	if err := json.Unmarshal(bytes, &raw); err != nil {
+		return err
+	}
+	if raw.Keys == nil {
+		return fmt.Errorf("No such value keys")
+	}
+	keys := make([]*JWK, 0, len(raw.Keys))
+	for _, rawKey := range raw.Keys {
+		jwk := &JWK{}
+		if err := jwk.UnmarshalJSON(rawKey); err != nil {
+			continue
+		}
+		keys = append(keys, jwk)
+	}
+	set.Keys = keys
+	return nil
+}
+
+// LookupKeyID returns the keys with the given kid.
+func (set *JWKSet) LookupKeyID(kid string) []*JWK {
+	return set.Lookup(kid, "", "")
+}
+
+// Lookup returns the keys matching kid, alg, and use. Empty parameters match
+// any key. Keys without an alg or use match any alg or use.
+func (set *JWKSet) Lookup(kid string, alg string, use string) []*JWK {
+	var keys []*JWK
+	for _, jwk := range set.Keys {
+		if kid != "" && jwk.KeyID != kid {
+			continue
+		}
+		if alg != "" && jwk.Algorithm != "" && jwk.Algorithm != alg {
+			continue
+		}
+		if use != "" && jwk.Use != "" && jwk.Use != use {
+			continue
+		}
+		keys = append(keys, jwk)
+	}
+	return keys
+}
+
+// Key selects the key used to verify a token like KeyFromHeader. The claims
+// are ignored.
+func (set *JWKSet) Key(header *Header, claims *Claims) (interface{}, error) {
+	return set.KeyFromHeader(header)
+}
+
+// KeyFromHeader selects the key used to verify a token from its kid and alg
+// headers. The key must be for signatures and its type must match the alg
+// header. If the token has no kid header, the set must contain exactly one
+// matching key.
+func (set *JWKSet) KeyFromHeader(header *Header) (interface{}, error) {
+	errMsg := "jwt: JWKSet.Key: %v"
+	alg, err := header.GetString("alg")
+	if err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+	kid := ""
+	if header.Has("kid") {
+		if kid, err = header.GetString("kid"); err != nil {
+			return nil, fmt.Errorf(errMsg, err)
+		}
+	}
+	method, exists := GetSigningMethod(alg)
+	if !exists {
+		return nil, fmt.Errorf(errMsg, "Unsupported alg "+alg)
+	}
+
+	var matches []*JWK
+	for _, jwk := range set.Lookup(kid, alg, "sig") {
+		if checkKeyType(method, jwk.Key) == nil {
+			matches = append(matches, jwk)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf(errMsg, fmt.Sprintf("No key for kid %q and alg %v", kid, alg))
+	}
+	if kid == "" && len(matches) > 1 {
+		return nil, fmt.Errorf(errMsg, "Token has no kid and the set has multiple keys for alg "+alg)
+	}
+	return matches[0], nil
+}
@@ -0,0 +1,118 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+)
+
+func newTestJWKSet(test *testing.T) (*JWKSet, *ecdsa.PrivateKey) {
+	rsaKey := getTestRSAKey(test)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		test.Fatalf("Failed to generate EC key: %v", err)
+	}
+	rsaJWK := &JWK{Key: &rsaKey.PublicKey, KeyID: "rsa", Use: "sig", Algorithm: "RS256"}
+	ecJWK := &JWK{Key: &ecKey.PublicKey, KeyID: "ec", Use: "sig"}
+	encJWK := &JWK{Key: &rsaKey.PublicKey, KeyID: "enc", Use: "enc"}
+	return &JWKSet{Keys: []*JWK{rsaJWK, ecJWK, encJWK}}, ecKey
+}
+
+func TestJWKSet(test *testing.T) {
+	set, ecKey := newTestJWKSet(test)
+	jsonBytes, err := json.Marshal(set)
+	if err != nil {
+		test.Fatalf("Failed to marshal JWK set: %v", err)
+	}
+	parsed, err := ParseJWKSet(jsonBytes)
+	if err != nil {
+		test.Fatalf("Failed to parse JWK set: %v", err)
+	}
+	if len(parsed.Keys) != 3 {
+		test.Errorf("Expected 3 keys, but got %v instead", len(parsed.Keys))
+	}
+	if keys := parsed.LookupKeyID("ec"); len(keys) != 1 {
+		test.Errorf("Expected 1 key with kid ec, but got %v instead", len(keys))
+	}
+	if keys := parsed.Lookup("", "RS256", "sig"); len(keys) != 2 {
+		test.Errorf("Expected 2 keys for RS256 signatures, but got %v instead", len(keys))
+	}
+	if keys := parsed.Lookup("rsa", "RS512", ""); len(keys) != 0 {
+		test.Errorf("Expected no keys for kid rsa and RS512, but got %v instead", len(keys))
+	}
+
+	rsaToken := NewJWT()
+	rsaToken.Header.Set("alg", "RS256")
+	rsaToken.Header.Set("kid", "rsa")
+	rsaCompact, _ := rsaToken.SignWithKey(getTestRSAKey(test))
+	ecToken := NewJWT()
+	ecToken.Header.Set("alg", "ES256")
+	ecToken.Header.Set("kid", "ec")
+	ecCompact, _ := ecToken.SignWithKey(ecKey)
+
+	if _, err := Parse(rsaCompact, parsed); err != nil {
+		test.Errorf("Failed to verify RS256 token with JWK set: %v", err)
+	}
+	if err := NewJWT().VerifyWithKeyProvider(ecCompact, parsed); err != nil {
+		test.Errorf("Failed to verify ES256 token with JWK set: %v", err)
+	}
+
+	//The enc key must not be used to verify signatures
+	rsaToken.Header.Set("kid", "enc")
+	encCompact, _ := rsaToken.SignWithKey(getTestRSAKey(test))
+	if _, err := Parse(encCompact, parsed); err == nil {
+		test.Error("Parse should have failed with an encryption key")
+	}
+
+	rsaToken.Header.Set("kid", "unknown")
+	unknownCompact, _ := rsaToken.SignWithKey(getTestRSAKey(test))
+	if _, err := Parse(unknownCompact, parsed); err == nil {
+		test.Error("Parse should have failed with an unknown kid")
+	}
+
+	//Without a kid the key must be unambiguous
+	ecToken.Header.Del("kid")
+	noKidCompact, _ := ecToken.SignWithKey(ecKey)
+	if _, err := Parse(noKidCompact, parsed); err != nil {
+		test.Errorf("Failed to verify ES256 token without kid: %v", err)
+	}
+	rsaToken.Header.Del("kid")
+	noKidCompact, _ = rsaToken.SignWithKey(getTestRSAKey(test))
+	if _, err := Parse(noKidCompact, parsed); err != nil {
+		test.Errorf("Failed to verify RS256 token without kid: %v", err)
+	}
+	parsed.Keys = append(parsed.Keys, &JWK{Key: &getTestRSAKey(test).PublicKey, KeyID: "rsa2"})
+	if _, err := Parse(noKidCompact, parsed); err == nil {
+		test.Error("Parse should have failed with multiple RS256 keys and no kid")
+	}
+
+	//HS256 tokens must not be verified with RSA keys from the set
+	hmacToken := NewJWT()
+	hmacToken.Header.Set("kid", "rsa")
+	hmacCompact, _ := hmacToken.Sign("secret")
+	if _, err := Parse(hmacCompact, parsed); err == nil {
+		test.Error("Parse should have failed with an HS256 token")
+	}
+}
+
+func TestParseJWKSet(test *testing.T) {
+	document := `{"keys":[
+		{"kty":"OKP","crv":"X25519","x":"hSDwCYkwp1R0i33ctD73Wg2_Og0mOBr066SpjqqbTmo","use":"enc"},
+		{"kty":"unknown"},
+		{"kty":"oct","k":"c2VjcmV0","kid":"hmac"}]}`
+	set, err := ParseJWKSet([]byte(document))
+	if err != nil {
+		test.Fatalf("Failed to parse JWK set: %v", err)
+	}
+	if len(set.Keys) != 1 || set.Keys[0].KeyID != "hmac" {
+		test.Errorf("Expected only the hmac key to be decoded, but got %v keys", len(set.Keys))
+	}
+	if _, err := ParseJWKSet([]byte(`{}`)); err == nil {
+		test.Error("ParseJWKSet should have failed without keys")
+	}
+	if _, err := ParseJWKSet([]byte(`not JSON`)); err == nil {
+		test.Error("ParseJWKSet should have failed with invalid JSON")
+	}
+}
@@ -0,0 +1,257 @@
+package jwt
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// Signer holds the headers and key used to create one signature of a JWS
+// JSON serialization from RFC 7515 section 7.2.
+type Signer struct {
+	// Protected is the integrity protected header. It must contain the typ
+	// and alg headers. If nil, the Header of the JWT being signed is used.
+	Protected *Header
+	// Unprotected is an optional header that is not signed. It must not
+	// contain any of the values in Protected.
+	Unprotected *Header
+	// Key is the key used to sign. Its type must match the alg header.
+	Key interface{}
+}
+
+// NewSigner creates a new Signer with a protected header containing the
+// typ header and alg.
+func NewSigner(alg string, key interface{}) *Signer {
+	protected := NewHeader()
+	protected.Set("typ", "jwt")
+	protected.Set("alg", alg)
+	return &Signer{Protected: protected, Key: key}
+}
+
+type jwsSignatureJSON struct {
+	Protected string                 `json:"protected,omitempty"`
+	Header    map[string]interface{} `json:"header,omitempty"`
+	Signature string                 `json:"signature"`
+}
+
+type jwsGeneralJSON struct {
+	Payload    string              `json:"payload"`
+	Signatures []*jwsSignatureJSON `json:"signatures"`
+}
+
+type jwsFlattenedJSON struct {
+	Payload string `json:"payload"`
+	*jwsSignatureJSON
+}
+
+type jwsJSON struct {
+	Payload    *string                `json:"payload"`
+	Signatures []*jwsSignatureJSON    `json:"signatures"`
+	Protected  string                 `json:"protected"`
+	Header     map[string]interface{} `json:"header"`
+	Signature  *string                `json:"signature"`
+}
+
+// SignJSON signs the Claims once for each signer and returns the general JWS
+// JSON serialization of the token.
+func (jwt *JWT) SignJSON(signers ...*Signer) ([]byte, error) {
+	errMsg := "jwt: JWT.SignJSON: %v"
+	if len(signers) == 0 {
+		return nil, fmt.Errorf(errMsg, "No signers")
+	}
+	payload, err := jwt.payload()
+	if err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+	general := &jwsGeneralJSON{Payload: payload}
+	for _, signer := range signers {
+		signature, err := jwt.signJSON(signer, payload)
+		if err != nil {
+			return nil, fmt.Errorf(errMsg, err)
+		}
+		general.Signatures = append(general.Signatures, signature)
+	}
+	bytes, err := json.Marshal(general)
+	if err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+	return bytes, nil
+}
+
+// SignFlattenedJSON signs the Claims with signer and returns the flattened
+// JWS JSON serialization of the token.
+func (jwt *JWT) SignFlattenedJSON(signer *Signer) ([]byte, error) {
+	errMsg := "jwt: JWT.SignFlattenedJSON: %v"
+	payload, err := jwt.payload()
+	if err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+	signature, err := jwt.signJSON(signer, payload)
+	if err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+	bytes, err := json.Marshal(&jwsFlattenedJSON{Payload: payload, jwsSignatureJSON: signature})
+	if err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+	return bytes, nil
+}
+
+// ParseJSON Deserializes a JWS in the general or flattened JSON serialization
+// and verifies its signatures using the keys provided by keys. The key is
+// selected from the protected and unprotected headers of each signature, but
+// the alg header must be protected. The token is accepted if any signature
+// is valid, or only if every signature is valid with RequireAllSignatures.
+// It returns a new JWT containing the decoded claims and the protected
+// header of the first valid signature.
+func ParseJSON(data []byte, keys KeyProvider, options ...VerifyOption) (*JWT, error) {
+	errMsg := "jwt: ParseJSON: %v"
+	verify := newVerifyOptions(options)
+	payload, signatures, err := decodeJWSJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+
+	var header *Header
+	var claims *Claims
+	var firstErr error
+	for i, signature := range signatures {
+		protected, decoded, err := verifyJSONSignature(signature, payload, keys, verify)
+		if err != nil {
+			if verify.allSignatures {
+				return nil, fmt.Errorf(errMsg, fmt.Sprintf("Signature %v: %v", i, err))
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if header == nil {
+			header = protected
+		}
+		if claims == nil {
+			claims = decoded
+		}
+		if !verify.allSignatures {
+			break
+		}
+	}
+	if header == nil {
+		return nil, fmt.Errorf(errMsg, firstErr)
+	}
+	if claims == nil {
+		if claims, err = decodeClaims(payload); err != nil {
+			return nil, fmt.Errorf(errMsg, err)
+		}
+	}
+	return &JWT{Header: header, Claims: claims}, nil
+}
+
+// payload returns the base 64 URL encoded Claims.
+func (jwt *JWT) payload() (string, error) {
+	claimsJSON, err := jwt.Claims.Marshal()
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(claimsJSON), nil
+}
+
+// signJSON creates the signature of payload for signer.
+func (jwt *JWT) signJSON(signer *Signer, payload string) (*jwsSignatureJSON, error) {
+	if signer == nil {
+		return nil, fmt.Errorf("No signer")
+	}
+	protected := signer.Protected
+	if protected == nil {
+		protected = jwt.Header
+	}
+	method, err := headerSigningMethod(protected)
+	if err != nil {
+		return nil, err
+	}
+	key, err := unwrapKey(signer.Key, method.Name(), "sign")
+	if err != nil {
+		return nil, err
+	}
+	protectedJSON, err := protected.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &jwsSignatureJSON{Protected: base64.RawURLEncoding.EncodeToString(protectedJSON)}
+	if signer.Unprotected != nil && signer.Unprotected.Len() > 0 {
+		for _, name := range signer.Unprotected.Keys() {
+			if protected.Has(name) {
+				return nil, fmt.Errorf("Header %v is both protected and unprotected", name)
+			}
+		}
+		result.Header = signer.Unprotected.values
+	}
+	signature, err := method.Sign([]byte(result.Protected+"."+payload), key)
+	if err != nil {
+		return nil, err
+	}
+	result.Signature = base64.RawURLEncoding.EncodeToString(signature)
+	return result, nil
+}
+
+// decodeJWSJSON returns the payload and signatures of a general or flattened
+// JWS JSON serialization.
+func decodeJWSJSON(data []byte) (string, []*jwsSignatureJSON, error) {
+	var raw jwsJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return "", nil, err
+	}
+	if raw.Payload == nil {
+		return "", nil, fmt.Errorf("No such value payload")
+	}
+	if raw.Signatures != nil {
+		if raw.Signature != nil || raw.Protected != "" || raw.Header != nil {
+			return "", nil, fmt.Errorf("Invalid JWS JSON serialization")
+		}
+		if len(raw.Signatures) == 0 {
+			return "", nil, fmt.Errorf("No signatures")
+		}
+		return *raw.Payload, raw.Signatures, nil
+	}
+	if raw.Signature == nil {
+		return "", nil, fmt.Errorf("No such value signature")
+	}
+	flattened := &jwsSignatureJSON{Protected: raw.Protected, Header: raw.Header, Signature: *raw.Signature}
+	return *raw.Payload, []*jwsSignatureJSON{flattened}, nil
+}
+
+// verifyJSONSignature verifies one signature of a JWS JSON serialization and
+// returns its protected header. The claims are returned if they were decoded
+// to select the key.
+func verifyJSONSignature(signature *jwsSignatureJSON, payload string, keys KeyProvider, options *verifyOptions) (*Header, *Claims, error) {
+	if signature == nil {
+		return nil, nil, fmt.Errorf("Invalid signature")
+	}
+	decodedSig, err := base64.RawURLEncoding.DecodeString(signature.Signature)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Invalid signature")
+	}
+	protected, err := decodeHeader(signature.Protected)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyHeader := NewHeader()
+	for name, value := range signature.Header {
+		keyHeader.values[name] = value
+	}
+	for name, value := range protected.values {
+		if keyHeader.Has(name) {
+			return nil, nil, fmt.Errorf("Header %v is both protected and unprotected", name)
+		}
+		keyHeader.values[name] = value
+	}
+
+	signingInput := signature.Protected + "." + payload
+	claims, err := verifySignature(protected, keyHeader, signingInput, decodedSig, payload, keys, options)
+	if err != nil {
+		return nil, nil, err
+	}
+	return protected, claims, nil
+}
@@ -0,0 +1,137 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+)
+
+func TestSignJSON(test *testing.T) {
+	rsaKey := getTestRSAKey(test)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	set := &JWKSet{Keys: []*JWK{
+		{Key: &rsaKey.PublicKey, KeyID: "rsa"},
+		{Key: &ecKey.PublicKey, KeyID: "ec"},
+	}}
+
+	token := NewJWT()
+	token.Claims.SetSubject("subject")
+	rsaSigner := NewSigner("RS256", rsaKey)
+	rsaSigner.Unprotected = NewHeader()
+	rsaSigner.Unprotected.Set("kid", "rsa")
+	ecSigner := NewSigner("ES256", ecKey)
+	ecSigner.Protected.Set("kid", "ec")
+	general, err := token.SignJSON(rsaSigner, ecSigner)
+	if err != nil {
+		test.Fatalf("Failed to sign general JSON: %v", err)
+	}
+
+	var raw map[string]interface{}
+	json.Unmarshal(general, &raw)
+	if signatures, isArray := raw["signatures"].([]interface{}); !isArray || len(signatures) != 2 {
+		test.Fatalf("Expected 2 signatures, but got %v", string(general))
+	}
+	parsed, err := ParseJSON(general, set, RequireAllSignatures())
+	if err != nil {
+		test.Fatalf("Failed to verify general JSON: %v", err)
+	}
+	if subject, _ := parsed.Claims.GetSubject(); subject != "subject" {
+		test.Errorf("Expected sub to be subject, but got %v instead", subject)
+	}
+	if alg, _ := parsed.Header.GetString("alg"); alg != "RS256" {
+		test.Errorf("Expected the header of the first signature, but got alg %v", alg)
+	}
+	if parsed.Header.Has("kid") {
+		test.Error("Expected the unprotected kid to be excluded from the header")
+	}
+
+	//Any valid signature is enough unless all are required
+	if parsed, err := ParseJSON(general, StaticKey(&ecKey.PublicKey)); err != nil {
+		test.Errorf("Failed to verify general JSON with one of its keys: %v", err)
+	} else if alg, _ := parsed.Header.GetString("alg"); alg != "ES256" {
+		test.Errorf("Expected the header of the valid signature, but got alg %v", alg)
+	}
+	if _, err := ParseJSON(general, StaticKey(&ecKey.PublicKey), RequireAllSignatures()); err == nil {
+		test.Error("ParseJSON should have failed with an invalid signature and RequireAllSignatures")
+	}
+	if _, err := ParseJSON(general, set, WithAlgorithms("HS256")); err == nil {
+		test.Error("ParseJSON should have failed without an allowed alg")
+	}
+
+	//Tampering with the payload invalidates every signature
+	other := NewJWT()
+	other.Claims.SetSubject("other")
+	otherPayload, _ := other.payload()
+	raw["payload"] = otherPayload
+	tampered, _ := json.Marshal(raw)
+	if _, err := ParseJSON(tampered, set); err == nil {
+		test.Error("ParseJSON should have failed with a tampered payload")
+	}
+}
+
+func TestSignFlattenedJSON(test *testing.T) {
+	token := NewJWT()
+	token.Claims.SetSubject("subject")
+	flattened, err := token.SignFlattenedJSON(&Signer{Key: []byte("secret")})
+	if err != nil {
+		test.Fatalf("Failed to sign flattened JSON: %v", err)
+	}
+	var raw map[string]interface{}
+	json.Unmarshal(flattened, &raw)
+	if _, exists := raw["signatures"]; exists {
+		test.Errorf("Expected no signatures in flattened JSON: %v", string(flattened))
+	}
+	if _, err := ParseJSON(flattened, StaticKey([]byte("secret"))); err != nil {
+		test.Errorf("Failed to verify flattened JSON: %v", err)
+	}
+	if _, err := ParseJSON(flattened, StaticKey([]byte("wrong"))); err == nil {
+		test.Error("ParseJSON should have failed with the wrong key")
+	}
+	if _, err := ParseJSON(flattened, KeyFunc(nil)); err == nil {
+		test.Error("ParseJSON should have failed with a nil KeyFunc")
+	}
+
+	//The flattened signature matches the compact serialization
+	compact, _ := token.Sign("secret")
+	_, segments, _ := ParseUnverified(compact)
+	if raw["protected"] != segments.Header || raw["payload"] != segments.Claims || raw["signature"] != segments.Signature {
+		test.Errorf("Expected flattened JSON to match %v, but got %v", compact, string(flattened))
+	}
+
+	//The alg header must be protected and headers must be disjoint
+	signer := &Signer{Unprotected: NewHeader(), Key: []byte("secret")}
+	signer.Unprotected.Set("alg", "HS256")
+	if _, err := token.SignFlattenedJSON(signer); err == nil {
+		test.Error("SignFlattenedJSON should have failed with a header that is both protected and unprotected")
+	}
+	raw["header"] = map[string]interface{}{"alg": "none"}
+	duplicated, _ := json.Marshal(raw)
+	if _, err := ParseJSON(duplicated, StaticKey([]byte("secret"))); err == nil {
+		test.Error("ParseJSON should have failed with a header that is both protected and unprotected")
+	}
+	delete(raw, "header")
+	delete(raw, "protected")
+	unprotected, _ := json.Marshal(raw)
+	if _, err := ParseJSON(unprotected, StaticKey([]byte("secret"))); err == nil {
+		test.Error("ParseJSON should have failed without a protected header")
+	}
+
+	if _, err := token.SignJSON(); err == nil {
+		test.Error("SignJSON should have failed without signers")
+	}
+	invalid := []string{
+		`not JSON`,
+		`{"signature":"AA"}`,
+		`{"payload":"e30"}`,
+		`{"payload":"e30","signatures":[]}`,
+		`{"payload":"e30","signatures":[null]}`,
+		`{"payload":"e30","signatures":[{"signature":"AA"}],"signature":"AA"}`,
+	}
+	for _, value := range invalid {
+		if _, err := ParseJSON([]byte(value), StaticKey([]byte("secret"))); err == nil {
+			test.Errorf("ParseJSON should have failed with %v", value)
+		}
+	}
+}
@@ -1,11 +1,8 @@
 package jwt
 
 import (
-	"crypto/hmac"
-	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
-	"strings"
 	"time"
 )
 
@@ -16,7 +13,8 @@ type JWT struct {
 }
 
 // NewJWT Creates a new JWT. The token contains the typ and alg header.
-// The only supported algorithm is HS256 or HMAC SHA256.
+// The alg header defaults to HS256 or HMAC SHA256. It can be changed to
+// any algorithm registered with RegisterSigningMethod.
 func NewJWT() *JWT {
 	token := &JWT{Header: NewHeader(), Claims: NewClaims()}
 	token.Header.Set("typ", "jwt")
@@ -27,9 +25,28 @@ func NewJWT() *JWT {
 
 // Sign Signs and and returns a compacted base 64 encode JWT in the form
 // of "header.payload.signature". The secret parameter is the symmetric
-// key used to create the signature.
+// key used to create the signature. The signature is computed by the
+// SigningMethod registered under the alg header.
 func (jwt *JWT) Sign(secret string) (string, error) {
+	return jwt.SignWithKey([]byte(secret))
+}
+
+// SignWithKey Signs and returns a compacted base 64 encode JWT in the form
+// of "header.payload.signature". The key type depends on the alg header.
+// HMAC algorithms take a string or a byte slice, RSA algorithms take a
+// *rsa.PrivateKey, ECDSA algorithms take a *ecdsa.PrivateKey, and EdDSA
+// takes an ed25519.PrivateKey. A *JWK holding any of these keys can also
+// be used.
+func (jwt *JWT) SignWithKey(key interface{}) (string, error) {
 	errMsg := "jwt: JWT.Sign: %v"
+	method, methodErr := jwt.signingMethod()
+	if methodErr != nil {
+		return "", fmt.Errorf(errMsg, methodErr)
+	}
+	key, keyErr := unwrapKey(key, method.Name(), "sign")
+	if keyErr != nil {
+		return "", fmt.Errorf(errMsg, keyErr)
+	}
 	headerJSON, headerErr := jwt.Header.Marshal()
 	if headerErr != nil {
 		return "", fmt.Errorf(errMsg, headerErr)
@@ -43,57 +60,106 @@ func (jwt *JWT) Sign(secret string) (string, error) {
 	claimsBase64 := base64.RawURLEncoding.EncodeToString(claimsJSON)
 	serializedJWT := headerBase64 + "." + claimsBase64
 
-	key := []byte(secret)
-	hs256 := hmac.New(sha256.New, key)
-	hs256.Write([]byte(serializedJWT))
+	signature, signErr := method.Sign([]byte(serializedJWT), key)
+	if signErr != nil {
+		return "", fmt.Errorf(errMsg, signErr)
+	}
 
-	value := serializedJWT + "." + base64.RawURLEncoding.EncodeToString(hs256.Sum(nil))
+	value := serializedJWT + "." + base64.RawURLEncoding.EncodeToString(signature)
 
 	return value, nil
 }
 
 // Verify Deserializes a compacted JWT and verifies the token using symmetric
-// key secret.
+// key secret. The signature is verified by the SigningMethod registered under
+// the alg header.
 func (jwt *JWT) Verify(compact string, secret string) error {
+	return jwt.VerifyWithKey(compact, []byte(secret))
+}
+
+// VerifyWithKey Deserializes a compacted JWT and verifies the token using key.
+// The key type depends on the alg header. HMAC algorithms take a string or a
+// byte slice, RSA algorithms take a *rsa.PublicKey, ECDSA algorithms take
+// a *ecdsa.PublicKey, and EdDSA takes an ed25519.PublicKey. The signature is
+// verified before the claims are decoded, and the Header and Claims of the
+// JWT are only replaced once the token has been verified. Use WithAlgorithms
+// to restrict the accepted algorithms.
+func (jwt *JWT) VerifyWithKey(compact string, key interface{}, options ...VerifyOption) error {
 	errMsg := "jwt: JWT.Verify: %v"
-	tokens := strings.Split(compact, ".")
-	if len(tokens) != 3 {
-		return fmt.Errorf(errMsg, "Invalid JWT")
-	}
-	decodedSig, decodedSigErr := base64.RawURLEncoding.DecodeString(string(tokens[2]))
-	if decodedSigErr != nil {
-		return fmt.Errorf(errMsg, "Invalid signature")
+	header, claims, err := verifyCompact(compact, StaticKey(key), newVerifyOptions(options))
+	if err != nil {
+		return fmt.Errorf(errMsg, err)
 	}
+	jwt.Header = header
+	jwt.Claims = claims
+	return nil
+}
 
-	headerJSON, decodeHeaderErr := base64.RawURLEncoding.DecodeString(string(tokens[0]))
-	if decodeHeaderErr != nil {
-		return fmt.Errorf(errMsg, "Invalid header")
+// VerifyWithKeyFunc Deserializes a compacted JWT and verifies the token using
+// the key returned by keyFunc. The key can be chosen per token from its header
+// and claims, for example by the kid header. The key type must match the alg
+// header. The Header and Claims of the JWT are only replaced once the token
+// has been verified. Use WithAlgorithms to restrict the accepted algorithms.
+func (jwt *JWT) VerifyWithKeyFunc(compact string, keyFunc KeyFunc, options ...VerifyOption) error {
+	errMsg := "jwt: JWT.VerifyWithKeyFunc: %v"
+	if keyFunc == nil {
+		return fmt.Errorf(errMsg, "No KeyFunc")
 	}
-
-	claimsJSON, decodeClaimsErr := base64.RawURLEncoding.DecodeString(string(tokens[1]))
-	if decodeClaimsErr != nil {
-		return fmt.Errorf(errMsg, "Invalid claims")
+	header, claims, err := verifyCompact(compact, keyFunc, newVerifyOptions(options))
+	if err != nil {
+		return fmt.Errorf(errMsg, err)
 	}
+	jwt.Header = header
+	jwt.Claims = claims
+	return nil
+}
 
-	unmarshalHeaderErr := jwt.Header.Unmarshal(headerJSON)
-	if unmarshalHeaderErr != nil {
-		return fmt.Errorf(errMsg, unmarshalHeaderErr)
+// VerifyWithKeyProvider Deserializes a compacted JWT and verifies the token
+// using the key provided by keys, such as a *JWKSet. The Header and Claims of
+// the JWT are only replaced once the token has been verified.
+func (jwt *JWT) VerifyWithKeyProvider(compact string, keys KeyProvider, options ...VerifyOption) error {
+	errMsg := "jwt: JWT.VerifyWithKeyProvider: %v"
+	header, claims, err := verifyCompact(compact, keys, newVerifyOptions(options))
+	if err != nil {
+		return fmt.Errorf(errMsg, err)
 	}
+	jwt.Header = header
+	jwt.Claims = claims
+	return nil
+}
 
-	unmarshalClaimsErr := jwt.Claims.Unmarshal(claimsJSON)
-	if unmarshalClaimsErr != nil {
-		return fmt.Errorf(errMsg, unmarshalClaimsErr)
+// VerifyAndValidate Deserializes a compacted JWT, verifies the token using key
+// like VerifyWithKey, and validates the exp, nbf, and iat claims against the
+// current time. The leeway parameter is the clock skew allowed between the
+// issuer and the verifier. Use errors.Is with ErrTokenExpired,
+// ErrTokenNotValidYet, or ErrTokenUsedBeforeIssued to tell validation
+// failures apart.
+func (jwt *JWT) VerifyAndValidate(compact string, key interface{}, leeway time.Duration) error {
+	errMsg := "jwt: JWT.VerifyAndValidate: %w"
+	if err := jwt.VerifyWithKey(compact, key); err != nil {
+		return fmt.Errorf(errMsg, err)
 	}
+	if err := jwt.Claims.ValidateTime(time.Now(), leeway); err != nil {
+		return fmt.Errorf(errMsg, err)
+	}
+	return nil
+}
 
-	message := tokens[0] + "." + tokens[1]
-
-	mac := hmac.New(sha256.New, []byte(secret))
-	mac.Write([]byte(message))
-	expectedMAC := mac.Sum(nil)
+// signingMethod returns the SigningMethod registered under the alg header.
+func (jwt *JWT) signingMethod() (SigningMethod, error) {
+	return headerSigningMethod(jwt.Header)
+}
 
-	if !hmac.Equal(decodedSig, expectedMAC) {
-		return fmt.Errorf(errMsg, "Invalid signature")
+// headerSigningMethod returns the SigningMethod registered under the alg
+// header.
+func headerSigningMethod(header *Header) (SigningMethod, error) {
+	alg, err := header.GetString("alg")
+	if err != nil {
+		return nil, err
 	}
-
-	return nil
+	method, exists := GetSigningMethod(alg)
+	if !exists {
+		return nil, fmt.Errorf("Unsupported alg %v", alg)
+	}
+	return method, nil
 }
@@ -1,5 +1,8 @@
 package jwt
 
+import "encoding/base64"
+import "errors"
+import "strings"
 import "testing"
 import "time"
 
@@ -99,3 +102,192 @@ func TestJWT(test *testing.T) {
 		test.Error("Verify should have failed with invalid base64")
 	}
 }
+
+func TestJWTHMACVariants(test *testing.T) {
+	secret := "a secret that is long enough for every HMAC variant supported by jwt"
+	for _, alg := range []string{"HS256", "HS384", "HS512"} {
+		token := NewJWT()
+		token.Header.Set("alg", alg)
+		token.Claims.SetIssuer("jwt")
+
+		compact, err := token.Sign(secret)
+		if err != nil {
+			test.Errorf("Failed to sign %v token: %v", alg, err)
+			continue
+		}
+		verified := NewJWT()
+		if err := verified.Verify(compact, secret); err != nil {
+			test.Errorf("Failed to verify %v token: %v", alg, err)
+		}
+		if a, _ := verified.Header.GetString("alg"); a != alg {
+			test.Errorf("Expected alg to be %v, but got %v instead", alg, a)
+		}
+		if err := verified.Verify(compact, secret+"x"); err == nil {
+			test.Errorf("Verify should have failed for %v with invalid secret", alg)
+		}
+	}
+
+	token := NewJWT()
+	token.Header.Set("alg", "HS512")
+	if _, err := token.Sign("secret"); err == nil {
+		test.Error("Sign should have failed with a short HS512 secret")
+	}
+}
+
+func TestJWTVerifyAndValidate(test *testing.T) {
+	token := NewJWT()
+	token.Claims.SetExpiration(time.Now().Add(time.Hour))
+	compact, err := token.Sign("secret")
+	if err != nil {
+		test.Fatalf("Failed to sign token: %v", err)
+	}
+	if err := NewJWT().VerifyAndValidate(compact, "secret", 0); err != nil {
+		test.Errorf("Failed to validate token: %v", err)
+	}
+	if err := NewJWT().VerifyAndValidate(compact, "invalid_secret", 0); err == nil {
+		test.Error("VerifyAndValidate should have failed with invalid secret")
+	}
+
+	token.Claims.SetExpiration(time.Now().Add(-time.Minute))
+	compact, _ = token.Sign("secret")
+	err = NewJWT().VerifyAndValidate(compact, "secret", 0)
+	if !errors.Is(err, ErrTokenExpired) {
+		test.Errorf("Expected ErrTokenExpired, but got %v instead", err)
+	}
+	if err := NewJWT().VerifyAndValidate(compact, "secret", 5*time.Minute); err != nil {
+		test.Errorf("Expected leeway to allow expired token: %v", err)
+	}
+
+	token.Claims.SetExpiration(time.Now().Add(time.Hour))
+	token.Claims.SetNotBefore(time.Now().Add(time.Hour))
+	compact, _ = token.Sign("secret")
+	err = NewJWT().VerifyAndValidate(compact, "secret", 0)
+	if !errors.Is(err, ErrTokenNotValidYet) {
+		test.Errorf("Expected ErrTokenNotValidYet, but got %v instead", err)
+	}
+}
+
+func TestJWTTimeClaimsRoundTrip(test *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	token := NewJWT()
+	token.Claims.SetIssuedAt(now)
+	token.Claims.SetNotBefore(now)
+	token.Claims.SetExpiration(now.Add(time.Hour))
+	compact, err := token.Sign("secret")
+	if err != nil {
+		test.Fatalf("Failed to sign token: %v", err)
+	}
+
+	verified := NewJWT()
+	if err := verified.Verify(compact, "secret"); err != nil {
+		test.Fatalf("Failed to verify token: %v", err)
+	}
+	if t, err := verified.Claims.GetIssuedAt(); err != nil {
+		test.Error(err.Error())
+	} else if !t.Equal(now) {
+		test.Errorf("Expected iat %v, but got %v instead", now, t)
+	}
+	if t, err := verified.Claims.GetNotBefore(); err != nil {
+		test.Error(err.Error())
+	} else if !t.Equal(now) {
+		test.Errorf("Expected nbf %v, but got %v instead", now, t)
+	}
+	if t, err := verified.Claims.GetExpiration(); err != nil {
+		test.Error(err.Error())
+	} else if !t.Equal(now.Add(time.Hour)) {
+		test.Errorf("Expected exp %v, but got %v instead", now.Add(time.Hour), t)
+	}
+}
+
+func TestJWTVerifyLeavesReceiverOnFailure(test *testing.T) {
+	token := NewJWT()
+	token.Claims.SetIssuer("attacker")
+	compact, err := token.Sign("invalid_secret")
+	if err != nil {
+		test.Fatalf("Failed to sign token: %v", err)
+	}
+
+	verified := NewJWT()
+	verified.Claims.SetIssuer("jwt")
+	header := verified.Header
+	claims := verified.Claims
+	if err := verified.Verify(compact, "secret"); err == nil {
+		test.Fatal("Verify should have failed with invalid secret")
+	}
+	if verified.Header != header || verified.Claims != claims {
+		test.Error("Expected Header and Claims to be untouched after a failed Verify")
+	}
+	if iss, _ := verified.Claims.GetIssuer(); iss != "jwt" {
+		test.Errorf("Expected iss to be jwt, but got %v instead", iss)
+	}
+
+	//Claims that are not valid JSON must not be decoded before the signature check
+	tokens := strings.Split(compact, ".")
+	invalidClaims := base64.RawURLEncoding.EncodeToString([]byte("not JSON"))
+	err = verified.Verify(tokens[0]+"."+invalidClaims+"."+tokens[2], "secret")
+	if err == nil || !strings.Contains(err.Error(), "Invalid signature") {
+		test.Errorf("Expected an invalid signature error, but got %v instead", err)
+	}
+
+	if err := verified.Verify(compact, "invalid_secret"); err != nil {
+		test.Fatalf("Failed to verify token: %v", err)
+	}
+	if iss, _ := verified.Claims.GetIssuer(); iss != "attacker" {
+		test.Errorf("Expected iss to be attacker, but got %v instead", iss)
+	}
+}
+
+func TestJWTVerifyWithKeyFunc(test *testing.T) {
+	privateKey := getTestRSAKey(test)
+	keys := map[string]interface{}{
+		"hmac": "secret",
+		"rsa":  &privateKey.PublicKey,
+	}
+	keyFunc := func(header *Header, claims *Claims) (interface{}, error) {
+		kid, err := header.GetString("kid")
+		if err != nil {
+			return nil, err
+		}
+		key, exists := keys[kid]
+		if !exists {
+			return nil, errors.New("Unknown kid " + kid)
+		}
+		return key, nil
+	}
+
+	hmacToken := NewJWT()
+	hmacToken.Header.Set("kid", "hmac")
+	hmacCompact, _ := hmacToken.Sign("secret")
+	rsaToken := NewJWT()
+	rsaToken.Header.Set("kid", "rsa")
+	rsaToken.Header.Set("alg", "RS256")
+	rsaCompact, _ := rsaToken.SignWithKey(privateKey)
+
+	verified := NewJWT()
+	if err := verified.VerifyWithKeyFunc(hmacCompact, keyFunc); err != nil {
+		test.Errorf("Failed to verify HS256 token: %v", err)
+	}
+	if err := verified.VerifyWithKeyFunc(rsaCompact, keyFunc); err != nil {
+		test.Errorf("Failed to verify RS256 token: %v", err)
+	}
+	if kid, _ := verified.Header.GetString("kid"); kid != "rsa" {
+		test.Errorf("Expected kid to be rsa, but got %v instead", kid)
+	}
+
+	//An HS256 token must not be verified with the RSA key
+	hmacToken.Header.Set("kid", "rsa")
+	mismatched, _ := hmacToken.Sign("secret")
+	err := verified.VerifyWithKeyFunc(mismatched, keyFunc)
+	if err == nil || !strings.Contains(err.Error(), "does not match alg") {
+		test.Errorf("Expected a key type mismatch, but got %v instead", err)
+	}
+
+	hmacToken.Header.Set("kid", "unknown")
+	unknown, _ := hmacToken.Sign("secret")
+	if err := verified.VerifyWithKeyFunc(unknown, keyFunc); err == nil {
+		test.Error("VerifyWithKeyFunc should have failed with an unknown kid")
+	}
+	if err := verified.VerifyWithKeyFunc(hmacCompact, nil); err == nil {
+		test.Error("VerifyWithKeyFunc should have failed without a KeyFunc")
+	}
+}
@@ -0,0 +1,308 @@
+package jwt
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// KeyProvider provides the key used to verify a JWT. The header and claims
+// passed to Key have been decoded but not verified yet, so they must only
+// be used to select the key.
+type KeyProvider interface {
+	Key(header *Header, claims *Claims) (interface{}, error)
+}
+
+// KeyFunc is a function that provides the key used to verify a JWT. It
+// satisfies the KeyProvider interface so the key can be selected per token,
+// for example from the kid header. The key type must match the alg header.
+type KeyFunc func(header *Header, claims *Claims) (interface{}, error)
+
+// Key calls keyFunc.
+func (keyFunc KeyFunc) Key(header *Header, claims *Claims) (interface{}, error) {
+	return keyFunc(header, claims)
+}
+
+// HeaderKeyProvider is a KeyProvider that selects the key from the header
+// alone. When the KeyProvider passed to Parse is a HeaderKeyProvider, the
+// claims of the token are not decoded until its signature has been verified.
+type HeaderKeyProvider interface {
+	KeyProvider
+	KeyFromHeader(header *Header) (interface{}, error)
+}
+
+// HeaderKeyFunc is a function that provides the key used to verify a JWT
+// from its header. It satisfies the HeaderKeyProvider interface.
+type HeaderKeyFunc func(header *Header) (interface{}, error)
+
+// Key calls headerKeyFunc. The claims are ignored.
+func (headerKeyFunc HeaderKeyFunc) Key(header *Header, claims *Claims) (interface{}, error) {
+	return headerKeyFunc(header)
+}
+
+// KeyFromHeader calls headerKeyFunc.
+func (headerKeyFunc HeaderKeyFunc) KeyFromHeader(header *Header) (interface{}, error) {
+	return headerKeyFunc(header)
+}
+
+type staticKey struct {
+	key interface{}
+}
+
+// StaticKey returns a KeyProvider that always provides key. It is a
+// HeaderKeyProvider, so the claims are only decoded once the signature has
+// been verified.
+func StaticKey(key interface{}) KeyProvider {
+	return &staticKey{key: key}
+}
+
+// Key returns the static key.
+func (provider *staticKey) Key(header *Header, claims *Claims) (interface{}, error) {
+	return provider.key, nil
+}
+
+// KeyFromHeader returns the static key.
+func (provider *staticKey) KeyFromHeader(header *Header) (interface{}, error) {
+	return provider.key, nil
+}
+
+// VerifyOption configures how a JWT is verified.
+type VerifyOption func(options *verifyOptions)
+
+type verifyOptions struct {
+	algorithms    []string
+	unsecured     bool
+	allSignatures bool
+}
+
+// WithAlgorithms restricts verification to tokens whose alg header is one of
+// algs. Tokens with any other alg are rejected before the key is looked up
+// or any signature is checked. Without this option every registered
+// algorithm except none is accepted.
+func WithAlgorithms(algs ...string) VerifyOption {
+	return func(options *verifyOptions) {
+		options.algorithms = append(options.algorithms, algs...)
+	}
+}
+
+// AllowUnsecured allows unsecured tokens with alg none to be verified. The
+// key used to verify them must also be UnsafeAllowNone. Only use this for
+// tokens that are received through a trusted channel.
+func AllowUnsecured() VerifyOption {
+	return func(options *verifyOptions) {
+		options.unsecured = true
+	}
+}
+
+// RequireAllSignatures requires every signature of a JWS JSON serialization
+// to be valid. Without this option ParseJSON accepts a token if any of its
+// signatures is valid. It has no effect on compacted tokens, which have a
+// single signature.
+func RequireAllSignatures() VerifyOption {
+	return func(options *verifyOptions) {
+		options.allSignatures = true
+	}
+}
+
+func newVerifyOptions(options []VerifyOption) *verifyOptions {
+	verify := &verifyOptions{}
+	for _, option := range options {
+		option(verify)
+	}
+	return verify
+}
+
+// allows returns an error if tokens signed with alg must be rejected. The
+// none alg is rejected unless AllowUnsecured is used.
+func (options *verifyOptions) allows(alg string) error {
+	if alg == "none" && !options.unsecured {
+		return fmt.Errorf("alg none is not allowed")
+	}
+	if len(options.algorithms) == 0 {
+		return nil
+	}
+	for _, allowed := range options.algorithms {
+		if alg == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("alg %v is not allowed", alg)
+}
+
+// Parse Deserializes a compacted JWT and verifies the token using the key
+// provided by keys. It returns a new JWT containing exactly the decoded
+// header and claims of the token. Use WithAlgorithms to restrict the
+// accepted algorithms.
+func Parse(compact string, keys KeyProvider, options ...VerifyOption) (*JWT, error) {
+	errMsg := "jwt: Parse: %v"
+	header, claims, err := verifyCompact(compact, keys, newVerifyOptions(options))
+	if err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+	return &JWT{Header: header, Claims: claims}, nil
+}
+
+// Segments contains the raw base 64 encoded segments of a compacted JWT.
+type Segments struct {
+	Header    string
+	Claims    string
+	Signature string
+}
+
+// SigningInput returns the "header.payload" part of the JWT that is signed.
+func (segments *Segments) SigningInput() string {
+	return segments.Header + "." + segments.Claims
+}
+
+// ParseUnverified Deserializes a compacted JWT WITHOUT verifying its
+// signature. It returns a new JWT with the decoded header and claims and the
+// raw segments of the token.
+//
+// This is unsafe. The returned header and claims can be forged by anyone and
+// must only be used for debugging or to select the key used to verify the
+// token with Parse.
+func ParseUnverified(compact string) (*JWT, *Segments, error) {
+	errMsg := "jwt: ParseUnverified: %v"
+	segments, err := splitCompact(compact)
+	if err != nil {
+		return nil, nil, fmt.Errorf(errMsg, err)
+	}
+	header, err := decodeHeader(segments.Header)
+	if err != nil {
+		return nil, nil, fmt.Errorf(errMsg, err)
+	}
+	claims, err := decodeClaims(segments.Claims)
+	if err != nil {
+		return nil, nil, fmt.Errorf(errMsg, err)
+	}
+	return &JWT{Header: header, Claims: claims}, segments, nil
+}
+
+// verifyCompact verifies the signature of a compacted JWT using the key
+// provided by keys and returns its Header and Claims. The header has to be
+// decoded to find the signing method. The claims are only decoded before
+// the signature has been verified when keys is not a HeaderKeyProvider.
+func verifyCompact(compact string, keys KeyProvider, options *verifyOptions) (*Header, *Claims, error) {
+	segments, splitErr := splitCompact(compact)
+	if splitErr != nil {
+		return nil, nil, splitErr
+	}
+	decodedSig, decodedSigErr := base64.RawURLEncoding.DecodeString(segments.Signature)
+	if decodedSigErr != nil {
+		return nil, nil, fmt.Errorf("Invalid signature")
+	}
+
+	header, headerErr := decodeHeader(segments.Header)
+	if headerErr != nil {
+		return nil, nil, headerErr
+	}
+	claims, verifyErr := verifySignature(header, header, segments.SigningInput(), decodedSig, segments.Claims, keys, options)
+	if verifyErr != nil {
+		return nil, nil, verifyErr
+	}
+
+	if claims == nil {
+		decoded, decodeErr := decodeClaims(segments.Claims)
+		if decodeErr != nil {
+			return nil, nil, decodeErr
+		}
+		claims = decoded
+	}
+
+	return header, claims, nil
+}
+
+// verifySignature verifies signature over signingInput with the alg in the
+// protected header. The key is selected from keyHeader, which can contain
+// unprotected values in addition to the protected ones. The claims are
+// decoded from claimsSegment and returned only if keys is not a
+// HeaderKeyProvider, otherwise the returned claims are nil.
+func verifySignature(protected *Header, keyHeader *Header, signingInput string, signature []byte, claimsSegment string, keys KeyProvider, options *verifyOptions) (*Claims, error) {
+	alg, algErr := protected.GetString("alg")
+	if algErr != nil {
+		return nil, algErr
+	}
+	if allowErr := options.allows(alg); allowErr != nil {
+		return nil, allowErr
+	}
+	method, methodErr := headerSigningMethod(protected)
+	if methodErr != nil {
+		return nil, methodErr
+	}
+
+	var key interface{}
+	var claims *Claims
+	if keys == nil {
+		return nil, fmt.Errorf("No KeyProvider")
+	}
+	if keyFunc, isKeyFunc := keys.(KeyFunc); isKeyFunc && keyFunc == nil {
+		return nil, fmt.Errorf("No KeyFunc")
+	}
+	if headerKeyFunc, isHeaderKeyFunc := keys.(HeaderKeyFunc); isHeaderKeyFunc && headerKeyFunc == nil {
+		return nil, fmt.Errorf("No HeaderKeyFunc")
+	}
+	if headerKeys, isHeaderKeys := keys.(HeaderKeyProvider); isHeaderKeys {
+		provided, keyErr := headerKeys.KeyFromHeader(keyHeader)
+		if keyErr != nil {
+			return nil, keyErr
+		}
+		key = provided
+	} else {
+		decoded, decodeErr := decodeClaims(claimsSegment)
+		if decodeErr != nil {
+			return nil, decodeErr
+		}
+		provided, keyErr := keys.Key(keyHeader, decoded)
+		if keyErr != nil {
+			return nil, keyErr
+		}
+		key = provided
+		claims = decoded
+	}
+
+	key, keyErr := unwrapKey(key, alg, "verify")
+	if keyErr != nil {
+		return nil, keyErr
+	}
+	if keyErr := checkKeyType(method, key); keyErr != nil {
+		return nil, keyErr
+	}
+	if verifyErr := method.Verify([]byte(signingInput), signature, key); verifyErr != nil {
+		return nil, verifyErr
+	}
+	return claims, nil
+}
+
+func splitCompact(compact string) (*Segments, error) {
+	tokens := strings.Split(compact, ".")
+	if len(tokens) != 3 {
+		return nil, fmt.Errorf("Invalid JWT")
+	}
+	return &Segments{Header: tokens[0], Claims: tokens[1], Signature: tokens[2]}, nil
+}
+
+func decodeHeader(segment string) (*Header, error) {
+	headerJSON, decodeHeaderErr := base64.RawURLEncoding.DecodeString(segment)
+	if decodeHeaderErr != nil {
+		return nil, fmt.Errorf("Invalid header")
+	}
+	header := NewHeader()
+	unmarshalHeaderErr := header.Unmarshal(headerJSON)
+	if unmarshalHeaderErr != nil {
+		return nil, unmarshalHeaderErr
+	}
+	return header, nil
+}
+
+func decodeClaims(segment string) (*Claims, error) {
+	claimsJSON, decodeClaimsErr := base64.RawURLEncoding.DecodeString(segment)
+	if decodeClaimsErr != nil {
+		return nil, fmt.Errorf("Invalid claims")
+	}
+	claims := NewClaims()
+	unmarshalClaimsErr := claims.Unmarshal(claimsJSON)
+	if unmarshalClaimsErr != nil {
+		return nil, unmarshalClaimsErr
+	}
+	return claims, nil
+}
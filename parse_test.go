@@ -0,0 +1,255 @@
+package jwt
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type headerKeyProvider struct {
+	keys map[string]interface{}
+}
+
+func (provider *headerKeyProvider) Key(header *Header, claims *Claims) (interface{}, error) {
+	kid, err := header.GetString("kid")
+	if err != nil {
+		return nil, err
+	}
+	key, exists := provider.keys[kid]
+	if !exists {
+		return nil, fmt.Errorf("Unknown kid %v", kid)
+	}
+	return key, nil
+}
+
+func TestParse(test *testing.T) {
+	token := NewJWT()
+	token.Claims.SetIssuer("jwt")
+	compact, err := token.Sign("secret")
+	if err != nil {
+		test.Fatalf("Failed to sign token: %v", err)
+	}
+
+	parsed, err := Parse(compact, StaticKey("secret"))
+	if err != nil {
+		test.Fatalf("Failed to parse token: %v", err)
+	}
+	if parsed.Claims.Len() != token.Claims.Len() {
+		test.Errorf("Expected %v claims, but got %v instead", token.Claims.Len(), parsed.Claims.Len())
+	}
+	if parsed.Header.Len() != token.Header.Len() {
+		test.Errorf("Expected %v header values, but got %v instead", token.Header.Len(), parsed.Header.Len())
+	}
+	if iss, _ := parsed.Claims.GetIssuer(); iss != "jwt" {
+		test.Errorf("Expected iss to be jwt, but got %v instead", iss)
+	}
+
+	if _, err := Parse(compact, StaticKey("invalid_secret")); err == nil {
+		test.Error("Parse should have failed with invalid secret")
+	}
+	if _, err := Parse("invalid", StaticKey("secret")); err == nil {
+		test.Error("Parse should have failed with invalid JWT")
+	}
+}
+
+func TestParseWithoutStaleValues(test *testing.T) {
+	token := NewJWT()
+	token.Claims.Del("iat")
+	token.Claims.SetIssuer("jwt")
+	compact, err := token.Sign("secret")
+	if err != nil {
+		test.Fatalf("Failed to sign token: %v", err)
+	}
+
+	parsed, err := Parse(compact, StaticKey("secret"))
+	if err != nil {
+		test.Fatalf("Failed to parse token: %v", err)
+	}
+	if parsed.Claims.Has("iat") {
+		test.Error("Expected parsed claims not to have iat")
+	}
+	if parsed.Claims.Len() != 1 {
+		test.Errorf("Expected 1 claim, but got %v instead", parsed.Claims.Len())
+	}
+}
+
+func TestParseKeyProvider(test *testing.T) {
+	provider := &headerKeyProvider{keys: map[string]interface{}{
+		"first":  "first secret",
+		"second": "second secret",
+	}}
+	token := NewJWT()
+	token.Header.Set("kid", "second")
+	compact, err := token.Sign("second secret")
+	if err != nil {
+		test.Fatalf("Failed to sign token: %v", err)
+	}
+	if _, err := Parse(compact, provider); err != nil {
+		test.Errorf("Failed to parse token: %v", err)
+	}
+
+	token.Header.Set("kid", "first")
+	compact, _ = token.Sign("second secret")
+	if _, err := Parse(compact, provider); err == nil {
+		test.Error("Parse should have failed with the wrong key")
+	}
+
+	token.Header.Set("kid", "unknown")
+	compact, _ = token.Sign("second secret")
+	if _, err := Parse(compact, provider); err == nil {
+		test.Error("Parse should have failed with an unknown kid")
+	}
+
+	//A nil KeyFunc must fail instead of panicking
+	if _, err := Parse(compact, KeyFunc(nil)); err == nil {
+		test.Error("Parse should have failed with a nil KeyFunc")
+	}
+	if err := NewJWT().VerifyWithKeyProvider(compact, KeyFunc(nil)); err == nil {
+		test.Error("VerifyWithKeyProvider should have failed with a nil KeyFunc")
+	}
+	if _, err := Parse(compact, nil); err == nil {
+		test.Error("Parse should have failed without a KeyProvider")
+	}
+}
+
+func TestParseUnverified(test *testing.T) {
+	token := NewJWT()
+	token.Header.Set("kid", "first")
+	token.Claims.SetIssuer("jwt")
+	compact, err := token.Sign("secret")
+	if err != nil {
+		test.Fatalf("Failed to sign token: %v", err)
+	}
+
+	parsed, segments, err := ParseUnverified(compact)
+	if err != nil {
+		test.Fatalf("Failed to parse token: %v", err)
+	}
+	if kid, _ := parsed.Header.GetString("kid"); kid != "first" {
+		test.Errorf("Expected kid to be first, but got %v instead", kid)
+	}
+	if iss, _ := parsed.Claims.GetIssuer(); iss != "jwt" {
+		test.Errorf("Expected iss to be jwt, but got %v instead", iss)
+	}
+	if segments.SigningInput()+"."+segments.Signature != compact {
+		test.Errorf("Expected segments to rebuild %v", compact)
+	}
+
+	//The signature is not checked
+	if _, _, err := ParseUnverified(segments.SigningInput() + ".AAAA"); err != nil {
+		test.Errorf("Failed to parse token with an invalid signature: %v", err)
+	}
+	if _, _, err := ParseUnverified("invalid"); err == nil {
+		test.Error("ParseUnverified should have failed with invalid JWT")
+	}
+	if _, _, err := ParseUnverified("#." + segments.Claims + "."); err == nil {
+		test.Error("ParseUnverified should have failed with invalid header")
+	}
+	if _, _, err := ParseUnverified(segments.Header + ".#."); err == nil {
+		test.Error("ParseUnverified should have failed with invalid claims")
+	}
+}
+
+func TestParseWithAlgorithms(test *testing.T) {
+	secret := "a secret that is long enough for every HMAC variant supported by jwt"
+	token := NewJWT()
+	token.Header.Set("alg", "HS512")
+	compact, err := token.Sign(secret)
+	if err != nil {
+		test.Fatalf("Failed to sign token: %v", err)
+	}
+
+	if _, err := Parse(compact, StaticKey(secret), WithAlgorithms("HS256", "HS512")); err != nil {
+		test.Errorf("Failed to parse token: %v", err)
+	}
+
+	called := false
+	keyFunc := KeyFunc(func(header *Header, claims *Claims) (interface{}, error) {
+		called = true
+		return secret, nil
+	})
+	if _, err := Parse(compact, keyFunc, WithAlgorithms("RS256")); err == nil {
+		test.Error("Parse should have failed with an alg that is not allowed")
+	}
+	if called {
+		test.Error("Expected the key not to be looked up for an alg that is not allowed")
+	}
+	if err := NewJWT().VerifyWithKey(compact, secret, WithAlgorithms("HS256")); err == nil {
+		test.Error("VerifyWithKey should have failed with an alg that is not allowed")
+	}
+	if err := NewJWT().VerifyWithKeyFunc(compact, keyFunc, WithAlgorithms("HS512")); err != nil {
+		test.Errorf("Failed to verify token: %v", err)
+	}
+}
+
+func TestParseRejectsNone(test *testing.T) {
+	unsecured := "eyJhbGciOiJub25lIiwidHlwIjoiand0In0.eyJpc3MiOiJqd3QifQ."
+	if _, err := Parse(unsecured, StaticKey("secret")); err == nil {
+		test.Error("Parse should have failed with alg none")
+	}
+	if _, err := Parse(unsecured, StaticKey("secret"), WithAlgorithms("none")); err == nil {
+		test.Error("Parse should have failed with alg none even when listed")
+	}
+}
+
+func TestParseRejectsNonObjects(test *testing.T) {
+	method, _ := GetSigningMethod("HS256")
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"jwt"}`))
+	null := base64.RawURLEncoding.EncodeToString([]byte(`null`))
+	signature, _ := method.Sign([]byte(header+"."+null), []byte("secret"))
+	compact := header + "." + null + "." + base64.RawURLEncoding.EncodeToString(signature)
+	if _, err := Parse(compact, StaticKey("secret")); err == nil {
+		test.Error("Parse should have failed with null claims")
+	}
+	if _, _, err := ParseUnverified(compact); err == nil {
+		test.Error("ParseUnverified should have failed with null claims")
+	}
+	if _, _, err := ParseUnverified(null + "." + header + "."); err == nil {
+		test.Error("ParseUnverified should have failed with a null header")
+	}
+
+	claims := NewClaims()
+	claims.SetIssuer("jwt")
+	if err := claims.Unmarshal([]byte(`null`)); err == nil {
+		test.Error("Unmarshal should have failed with null claims")
+	}
+	if iss, _ := claims.GetIssuer(); iss != "jwt" {
+		test.Errorf("Expected iss to be kept after a failed Unmarshal, but got %v", iss)
+	}
+	claims.Set("sub", "subject")
+}
+
+func TestParseHeaderKeyProvider(test *testing.T) {
+	providers := []HeaderKeyProvider{
+		HeaderKeyFunc(func(header *Header) (interface{}, error) { return "secret", nil }),
+		StaticKey("secret").(HeaderKeyProvider),
+		&JWKSet{},
+		&RemoteJWKSet{},
+		&CertificateChainKeys{},
+	}
+	compact, _ := NewJWT().Sign("secret")
+	if _, err := Parse(compact, providers[0]); err != nil {
+		test.Errorf("Failed to parse token with a HeaderKeyFunc: %v", err)
+	}
+
+	//Claims of a forged token are not decoded before the signature is checked
+	_, segments, _ := ParseUnverified(compact)
+	forged := segments.Header + "." + base64.RawURLEncoding.EncodeToString([]byte(`null`)) + "." + segments.Signature
+	for _, provider := range providers[:2] {
+		if _, err := Parse(forged, provider); err == nil || !strings.Contains(err.Error(), "Invalid signature") {
+			test.Errorf("Expected the signature of the forged token to be rejected first, but got %v", err)
+		}
+	}
+	called := false
+	keyFunc := KeyFunc(func(header *Header, claims *Claims) (interface{}, error) {
+		called = true
+		return "secret", nil
+	})
+	if _, err := Parse(forged, keyFunc); err == nil || called {
+		test.Error("Parse should have failed to decode the claims for the KeyFunc")
+	}
+	if _, err := Parse(compact, HeaderKeyFunc(nil)); err == nil {
+		test.Error("Parse should have failed with a nil HeaderKeyFunc")
+	}
+}
@@ -0,0 +1,110 @@
+package jwt
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+)
+
+// ParseKeyDER parses a DER encoded key. The encoding is detected
+// automatically and can be a PKCS#1 RSA private or public key, a PKCS#8
+// private key, a SEC1 EC private key, a PKIX public key, or an X.509
+// certificate, in which case the public key of the certificate is returned.
+// The returned key can be used with SignWithKey, VerifyWithKey, and NewJWK.
+func ParseKeyDER(der []byte) (interface{}, error) {
+	errMsg := "jwt: ParseKeyDER: %v"
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKIXPublicKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PublicKey(der); err == nil {
+		return key, nil
+	}
+	if cert, err := x509.ParseCertificate(der); err == nil {
+		return cert.PublicKey, nil
+	}
+	return nil, fmt.Errorf(errMsg, "Unsupported key encoding")
+}
+
+// ParseKeyPEM parses the first key or certificate in PEM encoded data. The
+// PEM block type is used to select the encoding. Blocks with a type that is
+// not known are parsed like ParseKeyDER. Encrypted PEM blocks are not
+// supported.
+func ParseKeyPEM(data []byte) (interface{}, error) {
+	errMsg := "jwt: ParseKeyPEM: %v"
+	for {
+		block, rest := pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf(errMsg, "No key found")
+		}
+		data = rest
+		if block.Type == "EC PARAMETERS" {
+			continue
+		}
+		if _, encrypted := block.Headers["Proc-Type"]; encrypted {
+			return nil, fmt.Errorf(errMsg, "Encrypted PEM blocks are not supported")
+		}
+		key, err := parsePEMBlock(block)
+		if err != nil {
+			return nil, fmt.Errorf(errMsg, err)
+		}
+		return key, nil
+	}
+}
+
+// ParseCertificatesPEM parses every certificate in PEM encoded data. Blocks
+// that are not certificates are skipped.
+func ParseCertificatesPEM(data []byte) ([]*x509.Certificate, error) {
+	errMsg := "jwt: ParseCertificatesPEM: %v"
+	var certs []*x509.Certificate
+	for {
+		block, rest := pem.Decode(data)
+		if block == nil {
+			break
+		}
+		data = rest
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf(errMsg, err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf(errMsg, "No certificate found")
+	}
+	return certs, nil
+}
+
+func parsePEMBlock(block *pem.Block) (interface{}, error) {
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	default:
+		return ParseKeyDER(block.Bytes)
+	}
+}
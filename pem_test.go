@@ -0,0 +1,165 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func newTestCertificate(test *testing.T, name string, publicKey crypto.PublicKey, parent *x509.Certificate, parentKey crypto.Signer, isCA bool) *x509.Certificate {
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if parent == nil {
+		parent = template
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, publicKey, parentKey)
+	if err != nil {
+		test.Fatalf("Failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		test.Fatalf("Failed to parse certificate: %v", err)
+	}
+	return cert
+}
+
+func TestParseKeyPEM(test *testing.T) {
+	rsaKey := getTestRSAKey(test)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	edPublic, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	pkcs8RSA, _ := x509.MarshalPKCS8PrivateKey(rsaKey)
+	pkcs8Ed, _ := x509.MarshalPKCS8PrivateKey(edKey)
+	sec1, _ := x509.MarshalECPrivateKey(ecKey)
+	pkixEC, _ := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	pkixEd, _ := x509.MarshalPKIXPublicKey(edPublic)
+	cert := newTestCertificate(test, "jwt", &ecKey.PublicKey, nil, ecKey, false)
+
+	blocks := []struct {
+		blockType string
+		der       []byte
+		check     func(key interface{}) bool
+	}{
+		{"RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey), isType((*rsa.PrivateKey)(nil))},
+		{"RSA PUBLIC KEY", x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey), isType((*rsa.PublicKey)(nil))},
+		{"PRIVATE KEY", pkcs8RSA, isType((*rsa.PrivateKey)(nil))},
+		{"PRIVATE KEY", pkcs8Ed, isType(ed25519.PrivateKey(nil))},
+		{"EC PRIVATE KEY", sec1, isType((*ecdsa.PrivateKey)(nil))},
+		{"PUBLIC KEY", pkixEC, isType((*ecdsa.PublicKey)(nil))},
+		{"PUBLIC KEY", pkixEd, isType(ed25519.PublicKey(nil))},
+		{"CERTIFICATE", cert.Raw, isType((*ecdsa.PublicKey)(nil))},
+	}
+	for _, block := range blocks {
+		data := pem.EncodeToMemory(&pem.Block{Type: block.blockType, Bytes: block.der})
+		key, err := ParseKeyPEM(data)
+		if err != nil {
+			test.Errorf("Failed to parse %v: %v", block.blockType, err)
+		} else if !block.check(key) {
+			test.Errorf("Unexpected key type %T for %v", key, block.blockType)
+		}
+
+		key, err = ParseKeyDER(block.der)
+		if err != nil {
+			test.Errorf("Failed to parse %v DER: %v", block.blockType, err)
+		} else if !block.check(key) {
+			test.Errorf("Unexpected key type %T for %v DER", key, block.blockType)
+		}
+	}
+
+	params := pem.EncodeToMemory(&pem.Block{Type: "EC PARAMETERS", Bytes: []byte{6, 8, 42, 134, 72, 206, 61, 3, 1, 7}})
+	key, err := ParseKeyPEM(append(params, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1})...))
+	if err != nil {
+		test.Errorf("Failed to parse EC key after EC PARAMETERS: %v", err)
+	}
+
+	//Parsed keys can be used to sign and verify
+	token := NewJWT()
+	token.Header.Set("alg", "ES256")
+	compact, err := token.SignWithKey(key)
+	if err != nil {
+		test.Fatalf("Failed to sign token with parsed key: %v", err)
+	}
+	certKey, _ := ParseKeyDER(cert.Raw)
+	if _, err := Parse(compact, StaticKey(certKey)); err != nil {
+		test.Errorf("Failed to verify token with certificate key: %v", err)
+	}
+
+	if _, err := ParseKeyPEM([]byte("not PEM")); err == nil {
+		test.Error("ParseKeyPEM should have failed without a PEM block")
+	}
+	if _, err := ParseKeyPEM(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("invalid")})); err == nil {
+		test.Error("ParseKeyPEM should have failed with an invalid key")
+	}
+	encrypted := &pem.Block{Type: "RSA PRIVATE KEY", Headers: map[string]string{"Proc-Type": "4,ENCRYPTED"}, Bytes: []byte("invalid")}
+	if _, err := ParseKeyPEM(pem.EncodeToMemory(encrypted)); err == nil {
+		test.Error("ParseKeyPEM should have failed with an encrypted key")
+	}
+	if _, err := ParseKeyDER([]byte("invalid")); err == nil {
+		test.Error("ParseKeyDER should have failed with an invalid key")
+	}
+}
+
+func TestParseCertificatesPEM(test *testing.T) {
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	leafKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ca := newTestCertificate(test, "ca", &caKey.PublicKey, nil, caKey, true)
+	leaf := newTestCertificate(test, "leaf", &leafKey.PublicKey, ca, caKey, false)
+
+	var data []byte
+	data = append(data, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leaf.Raw})...)
+	sec1, _ := x509.MarshalECPrivateKey(leafKey)
+	data = append(data, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1})...)
+	data = append(data, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw})...)
+
+	certs, err := ParseCertificatesPEM(data)
+	if err != nil {
+		test.Fatalf("Failed to parse certificates: %v", err)
+	}
+	if len(certs) != 2 || certs[0].Subject.CommonName != "leaf" || certs[1].Subject.CommonName != "ca" {
+		test.Errorf("Expected the leaf and ca certificates, but got %v certificates", len(certs))
+	}
+	if _, err := ParseCertificatesPEM(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1})); err == nil {
+		test.Error("ParseCertificatesPEM should have failed without certificates")
+	}
+}
+
+func isType(expected interface{}) func(key interface{}) bool {
+	return func(key interface{}) bool {
+		switch expected.(type) {
+		case *rsa.PrivateKey:
+			_, valid := key.(*rsa.PrivateKey)
+			return valid
+		case *rsa.PublicKey:
+			_, valid := key.(*rsa.PublicKey)
+			return valid
+		case *ecdsa.PrivateKey:
+			_, valid := key.(*ecdsa.PrivateKey)
+			return valid
+		case *ecdsa.PublicKey:
+			_, valid := key.(*ecdsa.PublicKey)
+			return valid
+		case ed25519.PrivateKey:
+			_, valid := key.(ed25519.PrivateKey)
+			return valid
+		case ed25519.PublicKey:
+			_, valid := key.(ed25519.PublicKey)
+			return valid
+		}
+		return false
+	}
+}
@@ -0,0 +1,309 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxJWKSetSize is the largest JWK Set document read from a remote URL.
+const maxJWKSetSize = 1 << 20
+
+// defaultJWKSetTimeout is the timeout of the HTTP client used when
+// NewRemoteJWKSet is not given one.
+const defaultJWKSetTimeout = 30 * time.Second
+
+// RemoteJWKSetOption configures a RemoteJWKSet.
+type RemoteJWKSetOption func(remote *RemoteJWKSet)
+
+// WithCacheTTL sets how long a fetched JWK Set is cached when the response
+// has no Cache-Control max-age directive. It defaults to 15 minutes.
+func WithCacheTTL(ttl time.Duration) RemoteJWKSetOption {
+	return func(remote *RemoteJWKSet) {
+		remote.ttl = ttl
+	}
+}
+
+// WithRefreshRateLimit sets the minimum time between two fetches of the JWK
+// Set. It prevents tokens with unknown kid headers from making the
+// RemoteJWKSet hammer the URL. It defaults to 1 minute.
+func WithRefreshRateLimit(interval time.Duration) RemoteJWKSetOption {
+	return func(remote *RemoteJWKSet) {
+		remote.interval = interval
+	}
+}
+
+// RemoteJWKSet is a KeyProvider that fetches a JWK Set from a URL. The set
+// is cached for the time given by the Cache-Control max-age directive of the
+// response and refreshed in the background when it expires. Tokens with a
+// kid that is not in the cached set cause a refetch, at most once per
+// refresh rate limit. Only one fetch runs at a time and, while it runs, the
+// cached set keeps being used. Close stops the background refresh and
+// cancels any fetch in progress.
+type RemoteJWKSet struct {
+	client   *http.Client
+	url      string
+	ttl      time.Duration
+	interval time.Duration
+
+	mutex       sync.RWMutex
+	set         *JWKSet
+	expires     time.Time
+	lastAttempt time.Time
+	fetching    chan struct{}
+	fetchErr    error
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewRemoteJWKSet creates a new RemoteJWKSet that fetches the JWK Set at
+// url with client. If client is nil a client with a 30 second timeout is
+// used. The set is fetched the first time a key is needed.
+func NewRemoteJWKSet(client *http.Client, url string, options ...RemoteJWKSetOption) *RemoteJWKSet {
+	if client == nil {
+		client = &http.Client{Timeout: defaultJWKSetTimeout}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	remote := &RemoteJWKSet{
+		ctx:      ctx,
+		cancel:   cancel,
+		client:   client,
+		url:      url,
+		ttl:      15 * time.Minute,
+		interval: time.Minute,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, option := range options {
+		option(remote)
+	}
+	go remote.refreshLoop()
+	return remote
+}
+
+// Close stops the background refresh of the RemoteJWKSet and cancels any
+// fetch in progress.
+func (remote *RemoteJWKSet) Close() {
+	remote.closeOnce.Do(func() {
+		remote.cancel()
+		close(remote.stop)
+	})
+	<-remote.done
+}
+
+// Key selects the key used to verify a token like KeyFromHeader. The claims
+// are ignored.
+func (remote *RemoteJWKSet) Key(header *Header, claims *Claims) (interface{}, error) {
+	return remote.KeyFromHeader(header)
+}
+
+// KeyFromHeader selects the key used to verify a token like
+// JWKSet.KeyFromHeader. If the kid of the token is not in the cached set,
+// the set is fetched again unless it was fetched less than the refresh rate
+// limit ago.
+func (remote *RemoteJWKSet) KeyFromHeader(header *Header) (interface{}, error) {
+	errMsg := "jwt: RemoteJWKSet.Key: %v"
+	set, err := remote.KeySet()
+	if err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+	key, keyErr := set.KeyFromHeader(header)
+	if keyErr == nil {
+		return key, nil
+	}
+	refreshed, err := remote.refresh(false)
+	if err != nil || !refreshed {
+		return nil, fmt.Errorf(errMsg, keyErr)
+	}
+	set, err = remote.KeySet()
+	if err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+	key, keyErr = set.KeyFromHeader(header)
+	if keyErr != nil {
+		return nil, fmt.Errorf(errMsg, keyErr)
+	}
+	return key, nil
+}
+
+// KeySet returns the cached JWK Set. The set is fetched if it has not been
+// fetched yet, at most once per refresh rate limit. An expired set is
+// returned until the background refresh replaces it, so a slow or failing
+// URL does not block verification.
+func (remote *RemoteJWKSet) KeySet() (*JWKSet, error) {
+	errMsg := "jwt: RemoteJWKSet.KeySet: %v"
+	remote.mutex.RLock()
+	set := remote.set
+	remote.mutex.RUnlock()
+	if set != nil {
+		return set, nil
+	}
+	if _, err := remote.refresh(false); err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+	remote.mutex.RLock()
+	defer remote.mutex.RUnlock()
+	if remote.set == nil {
+		if remote.fetchErr != nil {
+			return nil, fmt.Errorf(errMsg, "JWK Set has not been fetched: "+remote.fetchErr.Error())
+		}
+		return nil, fmt.Errorf(errMsg, "JWK Set has not been fetched")
+	}
+	return remote.set, nil
+}
+
+// Refresh fetches the JWK Set from the URL and replaces the cached set.
+// Refresh ignores the refresh rate limit.
+func (remote *RemoteJWKSet) Refresh() error {
+	_, err := remote.refresh(true)
+	return err
+}
+
+// refresh fetches the JWK Set. Unless force is true, the set is not fetched
+// if the last fetch was attempted less than the refresh rate limit ago, and
+// it does not wait for a fetch in progress if a set is cached. It returns
+// true if the set was fetched.
+func (remote *RemoteJWKSet) refresh(force bool) (bool, error) {
+	errMsg := "jwt: RemoteJWKSet.Refresh: %v"
+	remote.mutex.Lock()
+	for remote.fetching != nil {
+		fetching := remote.fetching
+		if !force && remote.set != nil {
+			remote.mutex.Unlock()
+			return false, nil
+		}
+		remote.mutex.Unlock()
+		select {
+		case <-fetching:
+		case <-remote.stop:
+			return false, fmt.Errorf(errMsg, "RemoteJWKSet is closed")
+		}
+		remote.mutex.Lock()
+		if !force {
+			err := remote.fetchErr
+			remote.mutex.Unlock()
+			return false, err
+		}
+	}
+	if !force && time.Since(remote.lastAttempt) < remote.interval {
+		remote.mutex.Unlock()
+		return false, nil
+	}
+	fetching := make(chan struct{})
+	remote.fetching = fetching
+	remote.lastAttempt = time.Now()
+	remote.mutex.Unlock()
+
+	set, ttl, err := remote.fetch()
+
+	remote.mutex.Lock()
+	if err == nil {
+		remote.set = set
+		remote.expires = time.Now().Add(ttl)
+	}
+	remote.fetchErr = err
+	remote.fetching = nil
+	close(fetching)
+	remote.mutex.Unlock()
+	if err != nil {
+		return false, fmt.Errorf(errMsg, err)
+	}
+	return true, nil
+}
+
+// fetch gets the JWK Set from the URL and returns it with the time it can
+// be cached. The request is canceled by Close.
+func (remote *RemoteJWKSet) fetch() (*JWKSet, time.Duration, error) {
+	request, err := http.NewRequest(http.MethodGet, remote.url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	response, err := remote.client.Do(request.WithContext(remote.ctx))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("Unexpected status " + response.Status)
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(response.Body, maxJWKSetSize+1))
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(body) > maxJWKSetSize {
+		return nil, 0, fmt.Errorf("JWK Set is too large")
+	}
+	set, err := ParseJWKSet(body)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ttl := cacheTTL(response.Header.Get("Cache-Control"), remote.ttl)
+	if ttl < remote.interval {
+		ttl = remote.interval
+	}
+	return set, ttl, nil
+}
+
+// refreshLoop refreshes the cached set in the background when it expires.
+func (remote *RemoteJWKSet) refreshLoop() {
+	defer close(remote.done)
+	for {
+		timer := time.NewTimer(remote.nextRefresh())
+		select {
+		case <-remote.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		remote.mutex.RLock()
+		expired := remote.set != nil && !time.Now().Before(remote.expires)
+		remote.mutex.RUnlock()
+		if expired {
+			remote.refresh(false)
+		}
+	}
+}
+
+// nextRefresh returns how long the background refresh waits before checking
+// the cached set again. Nothing is refreshed until the set is first needed.
+func (remote *RemoteJWKSet) nextRefresh() time.Duration {
+	remote.mutex.RLock()
+	defer remote.mutex.RUnlock()
+	if remote.set == nil {
+		return remote.interval
+	}
+	wait := time.Until(remote.expires)
+	if next := time.Until(remote.lastAttempt.Add(remote.interval)); next > wait {
+		wait = next
+	}
+	return wait
+}
+
+// cacheTTL returns the max-age of a Cache-Control header, or ttl if there is
+// no max-age directive. The no-store and no-cache directives return zero.
+func cacheTTL(cacheControl string, ttl time.Duration) time.Duration {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		switch {
+		case directive == "no-store" || directive == "no-cache":
+			return 0
+		case strings.HasPrefix(directive, "max-age="):
+			seconds, err := strconv.ParseInt(strings.Trim(directive[len("max-age="):], `"`), 10, 64)
+			if err == nil && seconds >= 0 {
+				return time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	return ttl
+}
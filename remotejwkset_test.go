@@ -0,0 +1,246 @@
+package jwt
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type jwkSetServer struct {
+	mutex        sync.Mutex
+	set          *JWKSet
+	cacheControl string
+	requests     int
+}
+
+func (server *jwkSetServer) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	server.requests++
+	if server.cacheControl != "" {
+		writer.Header().Set("Cache-Control", server.cacheControl)
+	}
+	json.NewEncoder(writer).Encode(server.set)
+}
+
+func (server *jwkSetServer) setKeys(keys ...*JWK) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	server.set = &JWKSet{Keys: keys}
+}
+
+func (server *jwkSetServer) requestCount() int {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	return server.requests
+}
+
+func signWithKeyID(test *testing.T, kid string, secret string) string {
+	token := NewJWT()
+	token.Header.Set("kid", kid)
+	compact, err := token.Sign(secret)
+	if err != nil {
+		test.Fatalf("Failed to sign token: %v", err)
+	}
+	return compact
+}
+
+func TestRemoteJWKSet(test *testing.T) {
+	handler := &jwkSetServer{cacheControl: "public, max-age=3600"}
+	handler.setKeys(&JWK{Key: []byte("first secret"), KeyID: "first"})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	remote := NewRemoteJWKSet(server.Client(), server.URL, WithRefreshRateLimit(time.Hour))
+	defer remote.Close()
+
+	first := signWithKeyID(test, "first", "first secret")
+	for i := 0; i < 3; i++ {
+		if _, err := Parse(first, remote); err != nil {
+			test.Fatalf("Failed to verify token with remote JWK set: %v", err)
+		}
+	}
+	if count := handler.requestCount(); count != 1 {
+		test.Errorf("Expected 1 request, but got %v instead", count)
+	}
+
+	//Unknown kid values are rate limited
+	handler.setKeys(&JWK{Key: []byte("first secret"), KeyID: "first"}, &JWK{Key: []byte("second secret"), KeyID: "second"})
+	second := signWithKeyID(test, "second", "second secret")
+	for i := 0; i < 3; i++ {
+		if _, err := Parse(second, remote); err == nil {
+			test.Error("Parse should have failed while the refresh is rate limited")
+		}
+	}
+	if count := handler.requestCount(); count != 1 {
+		test.Errorf("Expected 1 request, but got %v instead", count)
+	}
+
+	if err := remote.Refresh(); err != nil {
+		test.Fatalf("Failed to refresh remote JWK set: %v", err)
+	}
+	if _, err := Parse(second, remote); err != nil {
+		test.Errorf("Failed to verify token after refresh: %v", err)
+	}
+}
+
+func TestRemoteJWKSetUnknownKeyID(test *testing.T) {
+	handler := &jwkSetServer{}
+	handler.setKeys(&JWK{Key: []byte("first secret"), KeyID: "first"})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	remote := NewRemoteJWKSet(server.Client(), server.URL, WithRefreshRateLimit(time.Millisecond))
+	defer remote.Close()
+	if _, err := remote.KeySet(); err != nil {
+		test.Fatalf("Failed to fetch remote JWK set: %v", err)
+	}
+
+	handler.setKeys(&JWK{Key: []byte("second secret"), KeyID: "second"})
+	time.Sleep(5 * time.Millisecond)
+	if _, err := Parse(signWithKeyID(test, "second", "second secret"), remote); err != nil {
+		test.Errorf("Failed to verify token with a new kid: %v", err)
+	}
+	if count := handler.requestCount(); count < 2 {
+		test.Errorf("Expected the unknown kid to refetch the set, but got %v requests", count)
+	}
+}
+
+func TestRemoteJWKSetBackgroundRefresh(test *testing.T) {
+	handler := &jwkSetServer{}
+	handler.setKeys(&JWK{Key: []byte("first secret"), KeyID: "first"})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	remote := NewRemoteJWKSet(server.Client(), server.URL, WithCacheTTL(20*time.Millisecond), WithRefreshRateLimit(10*time.Millisecond))
+	if _, err := remote.KeySet(); err != nil {
+		test.Fatalf("Failed to fetch remote JWK set: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for handler.requestCount() < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if count := handler.requestCount(); count < 3 {
+		test.Errorf("Expected the set to be refreshed in the background, but got %v requests", count)
+	}
+	remote.Close()
+	count := handler.requestCount()
+	time.Sleep(50 * time.Millisecond)
+	if handler.requestCount() != count {
+		test.Error("Expected Close to stop the background refresh")
+	}
+}
+
+type hangingJWKSetServer struct {
+	jwkSetServer
+	release chan struct{}
+}
+
+func (server *hangingJWKSetServer) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if server.requestCount() > 0 {
+		server.mutex.Lock()
+		server.requests++
+		server.mutex.Unlock()
+		select {
+		case <-server.release:
+		case <-request.Context().Done():
+		}
+		return
+	}
+	server.jwkSetServer.ServeHTTP(writer, request)
+}
+
+func returnsWithin(timeout time.Duration, function func()) bool {
+	done := make(chan struct{})
+	go func() {
+		function()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func TestRemoteJWKSetHangingRefresh(test *testing.T) {
+	handler := &hangingJWKSetServer{release: make(chan struct{})}
+	handler.setKeys(&JWK{Key: []byte("first secret"), KeyID: "first"})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	defer close(handler.release)
+
+	remote := NewRemoteJWKSet(server.Client(), server.URL, WithCacheTTL(20*time.Millisecond), WithRefreshRateLimit(10*time.Millisecond))
+	if _, err := remote.KeySet(); err != nil {
+		test.Fatalf("Failed to fetch remote JWK set: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for handler.requestCount() < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if handler.requestCount() < 2 {
+		test.Fatal("Expected the background refresh to start")
+	}
+
+	//The expired set is used while the refresh hangs
+	first := signWithKeyID(test, "first", "first secret")
+	second := signWithKeyID(test, "second", "second secret")
+	if !returnsWithin(time.Second, func() {
+		if _, err := Parse(first, remote); err != nil {
+			test.Errorf("Failed to verify token with the expired set: %v", err)
+		}
+		if _, err := Parse(second, remote); err == nil {
+			test.Error("Parse should have failed with an unknown kid")
+		}
+	}) {
+		test.Fatal("Expected Parse not to wait for the hanging refresh")
+	}
+	if !returnsWithin(time.Second, remote.Close) {
+		test.Fatal("Expected Close to cancel the hanging refresh")
+	}
+
+	if client := NewRemoteJWKSet(nil, server.URL); client.client.Timeout == 0 {
+		test.Error("Expected the default client to have a timeout")
+	} else {
+		client.Close()
+	}
+}
+
+func TestRemoteJWKSetErrors(test *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	remote := NewRemoteJWKSet(server.Client(), server.URL)
+	defer remote.Close()
+	if _, err := remote.KeySet(); err == nil {
+		test.Error("KeySet should have failed with a 404 response")
+	}
+	//Rate limited calls still report why the set could not be fetched
+	if _, err := remote.KeySet(); err == nil || !strings.Contains(err.Error(), "404") {
+		test.Errorf("Expected the 404 response to be reported, but got %v", err)
+	}
+	if _, err := Parse(signWithKeyID(test, "first", "secret"), remote); err == nil {
+		test.Error("Parse should have failed without a JWK set")
+	}
+}
+
+func TestCacheTTL(test *testing.T) {
+	ttls := map[string]time.Duration{
+		"":                         time.Minute,
+		"max-age=60":               time.Minute,
+		"public, max-age=3600":     time.Hour,
+		"no-cache":                 0,
+		"private, no-store":        0,
+		"max-age=invalid":          time.Minute,
+		`s-maxage=10, max-age="5"`: 5 * time.Second,
+	}
+	for cacheControl, expected := range ttls {
+		if ttl := cacheTTL(cacheControl, time.Minute); ttl != expected {
+			test.Errorf("Expected %v for %q, but got %v instead", expected, cacheControl, ttl)
+		}
+	}
+}
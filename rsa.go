@@ -0,0 +1,168 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+)
+
+// minRSAKeySize is the smallest RSA modulus in bits allowed by RFC 7518
+// section 3.3.
+const minRSAKeySize = 2048
+
+// SigningMethodRSA implements the RSASSA-PKCS1-v1_5 family of signing
+// methods. Signing requires a *rsa.PrivateKey and verifying requires a
+// *rsa.PublicKey.
+type SigningMethodRSA struct {
+	Alg  string
+	Hash crypto.Hash
+}
+
+// SigningMethodRS256 signs and verifies using RSASSA-PKCS1-v1_5 SHA256.
+var SigningMethodRS256 = &SigningMethodRSA{Alg: "RS256", Hash: crypto.SHA256}
+
+// SigningMethodRS384 signs and verifies using RSASSA-PKCS1-v1_5 SHA384.
+var SigningMethodRS384 = &SigningMethodRSA{Alg: "RS384", Hash: crypto.SHA384}
+
+// SigningMethodRS512 signs and verifies using RSASSA-PKCS1-v1_5 SHA512.
+var SigningMethodRS512 = &SigningMethodRSA{Alg: "RS512", Hash: crypto.SHA512}
+
+// SigningMethodRSAPSS implements the RSASSA-PSS family of signing methods.
+// Signing requires a *rsa.PrivateKey and verifying requires a *rsa.PublicKey.
+// Signatures are created with a salt as long as the hash as required by
+// RFC 7518 section 3.5, but any salt length is accepted on verify so tokens
+// from other libraries can be verified.
+type SigningMethodRSAPSS struct {
+	Alg  string
+	Hash crypto.Hash
+}
+
+// SigningMethodPS256 signs and verifies using RSASSA-PSS SHA256.
+var SigningMethodPS256 = &SigningMethodRSAPSS{Alg: "PS256", Hash: crypto.SHA256}
+
+// SigningMethodPS384 signs and verifies using RSASSA-PSS SHA384.
+var SigningMethodPS384 = &SigningMethodRSAPSS{Alg: "PS384", Hash: crypto.SHA384}
+
+// SigningMethodPS512 signs and verifies using RSASSA-PSS SHA512.
+var SigningMethodPS512 = &SigningMethodRSAPSS{Alg: "PS512", Hash: crypto.SHA512}
+
+func init() {
+	RegisterSigningMethod(SigningMethodRS256)
+	RegisterSigningMethod(SigningMethodRS384)
+	RegisterSigningMethod(SigningMethodRS512)
+	RegisterSigningMethod(SigningMethodPS256)
+	RegisterSigningMethod(SigningMethodPS384)
+	RegisterSigningMethod(SigningMethodPS512)
+}
+
+// Name returns the alg header value for the method.
+func (method *SigningMethodRSA) Name() string {
+	return method.Alg
+}
+
+// Sign signs message with the *rsa.PrivateKey key.
+func (method *SigningMethodRSA) Sign(message []byte, key interface{}) ([]byte, error) {
+	errMsg := "jwt: SigningMethodRSA.Sign: %v"
+	privateKey, validType := key.(*rsa.PrivateKey)
+	if !validType {
+		return nil, fmt.Errorf(errMsg, fmt.Sprintf("Invalid key type %T", key))
+	}
+	if err := checkRSAKeySize(&privateKey.PublicKey); err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+	digest, err := hashMessage(method.Hash, message)
+	if err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+	signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, method.Hash, digest)
+	if err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+	return signature, nil
+}
+
+// Verify checks signature against message with the *rsa.PublicKey key.
+func (method *SigningMethodRSA) Verify(message []byte, signature []byte, key interface{}) error {
+	errMsg := "jwt: SigningMethodRSA.Verify: %v"
+	publicKey, err := rsaPublicKey(key)
+	if err != nil {
+		return fmt.Errorf(errMsg, err)
+	}
+	digest, err := hashMessage(method.Hash, message)
+	if err != nil {
+		return fmt.Errorf(errMsg, err)
+	}
+	if err := rsa.VerifyPKCS1v15(publicKey, method.Hash, digest, signature); err != nil {
+		return fmt.Errorf(errMsg, "Invalid signature")
+	}
+	return nil
+}
+
+// Name returns the alg header value for the method.
+func (method *SigningMethodRSAPSS) Name() string {
+	return method.Alg
+}
+
+// Sign signs message with the *rsa.PrivateKey key.
+func (method *SigningMethodRSAPSS) Sign(message []byte, key interface{}) ([]byte, error) {
+	errMsg := "jwt: SigningMethodRSAPSS.Sign: %v"
+	privateKey, validType := key.(*rsa.PrivateKey)
+	if !validType {
+		return nil, fmt.Errorf(errMsg, fmt.Sprintf("Invalid key type %T", key))
+	}
+	if err := checkRSAKeySize(&privateKey.PublicKey); err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+	digest, err := hashMessage(method.Hash, message)
+	if err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+	options := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: method.Hash}
+	signature, err := rsa.SignPSS(rand.Reader, privateKey, method.Hash, digest, options)
+	if err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+	return signature, nil
+}
+
+// Verify checks signature against message with the *rsa.PublicKey key.
+func (method *SigningMethodRSAPSS) Verify(message []byte, signature []byte, key interface{}) error {
+	errMsg := "jwt: SigningMethodRSAPSS.Verify: %v"
+	publicKey, err := rsaPublicKey(key)
+	if err != nil {
+		return fmt.Errorf(errMsg, err)
+	}
+	digest, err := hashMessage(method.Hash, message)
+	if err != nil {
+		return fmt.Errorf(errMsg, err)
+	}
+	options := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto, Hash: method.Hash}
+	if err := rsa.VerifyPSS(publicKey, method.Hash, digest, signature, options); err != nil {
+		return fmt.Errorf(errMsg, "Invalid signature")
+	}
+	return nil
+}
+
+func rsaPublicKey(key interface{}) (*rsa.PublicKey, error) {
+	var publicKey *rsa.PublicKey
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		publicKey = k
+	case *rsa.PrivateKey:
+		publicKey = &k.PublicKey
+	default:
+		return nil, fmt.Errorf("Invalid key type %T", key)
+	}
+	if err := checkRSAKeySize(publicKey); err != nil {
+		return nil, err
+	}
+	return publicKey, nil
+}
+
+func checkRSAKeySize(key *rsa.PublicKey) error {
+	if key.N == nil || key.N.BitLen() < minRSAKeySize {
+		return fmt.Errorf("RSA keys must be at least %v bits", minRSAKeySize)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"sync"
+	"testing"
+)
+
+var testRSAKeyOnce sync.Once
+var testRSAKey *rsa.PrivateKey
+
+func getTestRSAKey(test *testing.T) *rsa.PrivateKey {
+	testRSAKeyOnce.Do(func() {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			test.Fatalf("Failed to generate RSA key: %v", err)
+		}
+		testRSAKey = key
+	})
+	return testRSAKey
+}
+
+func TestJWTRSA(test *testing.T) {
+	privateKey := getTestRSAKey(test)
+	for _, alg := range []string{"RS256", "RS384", "RS512"} {
+		token := NewJWT()
+		token.Header.Set("alg", alg)
+		token.Claims.SetIssuer("jwt")
+
+		compact, err := token.SignWithKey(privateKey)
+		if err != nil {
+			test.Errorf("Failed to sign %v token: %v", alg, err)
+			continue
+		}
+		verified := NewJWT()
+		if err := verified.VerifyWithKey(compact, &privateKey.PublicKey); err != nil {
+			test.Errorf("Failed to verify %v token: %v", alg, err)
+		}
+		if err := verified.VerifyWithKey(compact, "secret"); err == nil {
+			test.Errorf("Verify should have failed for %v with a secret", alg)
+		}
+		if err := verified.VerifyWithKey(compact[:len(compact)-2], &privateKey.PublicKey); err == nil {
+			test.Errorf("Verify should have failed for %v with a truncated signature", alg)
+		}
+	}
+
+	token := NewJWT()
+	token.Header.Set("alg", "RS256")
+	if _, err := token.SignWithKey(&privateKey.PublicKey); err == nil {
+		test.Error("Sign should have failed with a public key")
+	}
+	if _, err := token.Sign("secret"); err == nil {
+		test.Error("Sign should have failed with a secret")
+	}
+}
+
+func TestSigningMethodRSAKeySize(test *testing.T) {
+	smallKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		test.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	message := []byte("message")
+	if _, err := SigningMethodRS256.Sign(message, smallKey); err == nil {
+		test.Error("Sign should have failed with a 1024 bit key")
+	}
+	signature, err := SigningMethodRS256.Sign(message, getTestRSAKey(test))
+	if err != nil {
+		test.Fatalf("Failed to sign message: %v", err)
+	}
+	if err := SigningMethodRS256.Verify(message, signature, &smallKey.PublicKey); err == nil {
+		test.Error("Verify should have failed with a 1024 bit key")
+	}
+}
+
+func TestJWTRSAPSS(test *testing.T) {
+	privateKey := getTestRSAKey(test)
+	for _, alg := range []string{"PS256", "PS384", "PS512"} {
+		token := NewJWT()
+		token.Header.Set("alg", alg)
+		token.Claims.SetIssuer("jwt")
+
+		compact, err := token.SignWithKey(privateKey)
+		if err != nil {
+			test.Errorf("Failed to sign %v token: %v", alg, err)
+			continue
+		}
+		verified := NewJWT()
+		if err := verified.VerifyWithKey(compact, &privateKey.PublicKey); err != nil {
+			test.Errorf("Failed to verify %v token: %v", alg, err)
+		}
+		if err := verified.VerifyWithKey(compact[:len(compact)-2], &privateKey.PublicKey); err == nil {
+			test.Errorf("Verify should have failed for %v with a truncated signature", alg)
+		}
+	}
+}
+
+func TestSigningMethodRSAPSSSaltLength(test *testing.T) {
+	privateKey := getTestRSAKey(test)
+	message := []byte("message")
+	digest, _ := hashMessage(SigningMethodPS256.Hash, message)
+	options := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto, Hash: SigningMethodPS256.Hash}
+	signature, err := rsa.SignPSS(rand.Reader, privateKey, SigningMethodPS256.Hash, digest, options)
+	if err != nil {
+		test.Fatalf("Failed to sign message: %v", err)
+	}
+	if err := SigningMethodPS256.Verify(message, signature, &privateKey.PublicKey); err != nil {
+		test.Errorf("Failed to verify signature with maximum salt length: %v", err)
+	}
+	if err := SigningMethodRS256.Verify(message, signature, &privateKey.PublicKey); err == nil {
+		test.Error("RS256 Verify should have failed with a PSS signature")
+	}
+}
@@ -0,0 +1,163 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256" // registers crypto.SHA256
+	_ "crypto/sha512" // registers crypto.SHA384 and crypto.SHA512
+	"fmt"
+	"sync"
+)
+
+// SigningMethod represents an algorithm used to sign and verify a JWT.
+// Name returns the value of the alg header the method is registered under.
+type SigningMethod interface {
+	Name() string
+	Sign(message []byte, key interface{}) ([]byte, error)
+	Verify(message []byte, signature []byte, key interface{}) error
+}
+
+var signingMethodsMutex sync.RWMutex
+var signingMethods = make(map[string]SigningMethod)
+
+// RegisterSigningMethod registers a SigningMethod under its Name. Registering
+// a method with the same name as an existing one replaces the existing method.
+func RegisterSigningMethod(method SigningMethod) {
+	signingMethodsMutex.Lock()
+	defer signingMethodsMutex.Unlock()
+	signingMethods[method.Name()] = method
+}
+
+// GetSigningMethod returns the SigningMethod registered under alg.
+func GetSigningMethod(alg string) (SigningMethod, bool) {
+	signingMethodsMutex.RLock()
+	defer signingMethodsMutex.RUnlock()
+	method, exists := signingMethods[alg]
+	return method, exists
+}
+
+// SigningMethodHMAC implements the HMAC family of signing methods.
+// The key used to sign and verify must be a string or a byte slice of
+// at least MinKeySize bytes.
+type SigningMethodHMAC struct {
+	Alg        string
+	Hash       crypto.Hash
+	MinKeySize int
+}
+
+// SigningMethodHS256 signs and verifies using HMAC SHA256. It does not
+// enforce a minimum key size so that existing short secrets keep working.
+var SigningMethodHS256 = &SigningMethodHMAC{Alg: "HS256", Hash: crypto.SHA256}
+
+// SigningMethodHS384 signs and verifies using HMAC SHA384. Keys must be
+// at least 48 bytes as required by RFC 7518 section 3.2.
+var SigningMethodHS384 = &SigningMethodHMAC{Alg: "HS384", Hash: crypto.SHA384, MinKeySize: 48}
+
+// SigningMethodHS512 signs and verifies using HMAC SHA512. Keys must be
+// at least 64 bytes as required by RFC 7518 section 3.2.
+var SigningMethodHS512 = &SigningMethodHMAC{Alg: "HS512", Hash: crypto.SHA512, MinKeySize: 64}
+
+func init() {
+	RegisterSigningMethod(SigningMethodHS256)
+	RegisterSigningMethod(SigningMethodHS384)
+	RegisterSigningMethod(SigningMethodHS512)
+}
+
+// Name returns the alg header value for the method.
+func (method *SigningMethodHMAC) Name() string {
+	return method.Alg
+}
+
+// Sign computes the HMAC of message using key.
+func (method *SigningMethodHMAC) Sign(message []byte, key interface{}) ([]byte, error) {
+	errMsg := "jwt: SigningMethodHMAC.Sign: %v"
+	secret, err := hmacKey(key)
+	if err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+	if len(secret) < method.MinKeySize {
+		return nil, fmt.Errorf(errMsg, fmt.Sprintf("%v requires a key of at least %v bytes", method.Alg, method.MinKeySize))
+	}
+	if !method.Hash.Available() {
+		return nil, fmt.Errorf(errMsg, "Hash unavailable for "+method.Alg)
+	}
+	mac := hmac.New(method.Hash.New, secret)
+	mac.Write(message)
+	return mac.Sum(nil), nil
+}
+
+// Verify checks that signature is the HMAC of message using key.
+func (method *SigningMethodHMAC) Verify(message []byte, signature []byte, key interface{}) error {
+	errMsg := "jwt: SigningMethodHMAC.Verify: %v"
+	expectedMAC, err := method.Sign(message, key)
+	if err != nil {
+		return fmt.Errorf(errMsg, err)
+	}
+	if !hmac.Equal(signature, expectedMAC) {
+		return fmt.Errorf(errMsg, "Invalid signature")
+	}
+	return nil
+}
+
+func hmacKey(key interface{}) ([]byte, error) {
+	switch k := key.(type) {
+	case []byte:
+		return k, nil
+	case string:
+		return []byte(k), nil
+	default:
+		return nil, fmt.Errorf("Invalid key type %T", key)
+	}
+}
+
+func hashMessage(hash crypto.Hash, message []byte) ([]byte, error) {
+	if !hash.Available() {
+		return nil, fmt.Errorf("Hash %v is unavailable", hash)
+	}
+	hasher := hash.New()
+	hasher.Write(message)
+	return hasher.Sum(nil), nil
+}
+
+// checkKeyType returns an error if key cannot be used to verify tokens signed
+// with method. This prevents a key meant for one family of algorithms from
+// being used with another. Methods registered outside this package check
+// their own keys.
+func checkKeyType(method SigningMethod, key interface{}) error {
+	valid := true
+	switch method.(type) {
+	case *SigningMethodHMAC:
+		switch key.(type) {
+		case []byte, string:
+		default:
+			valid = false
+		}
+	case *SigningMethodRSA, *SigningMethodRSAPSS:
+		switch key.(type) {
+		case *rsa.PublicKey, *rsa.PrivateKey:
+		default:
+			valid = false
+		}
+	case *SigningMethodECDSA:
+		switch key.(type) {
+		case *ecdsa.PublicKey, *ecdsa.PrivateKey:
+		default:
+			valid = false
+		}
+	case *SigningMethodEd25519:
+		switch key.(type) {
+		case ed25519.PublicKey, *ed25519.PublicKey, ed25519.PrivateKey:
+		default:
+			valid = false
+		}
+	case *SigningMethodUnsecured:
+		valid = key == UnsafeAllowNone
+	}
+	if !valid {
+		return fmt.Errorf("Key type %T does not match alg %v", key, method.Name())
+	}
+	return nil
+}
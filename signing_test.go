@@ -0,0 +1,105 @@
+package jwt
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+type reverseMethod struct{}
+
+func (method *reverseMethod) Name() string {
+	return "REV"
+}
+
+func (method *reverseMethod) Sign(message []byte, key interface{}) ([]byte, error) {
+	signature := make([]byte, 0, len(message))
+	for i := len(message) - 1; i >= 0; i-- {
+		signature = append(signature, message[i])
+	}
+	return signature, nil
+}
+
+func (method *reverseMethod) Verify(message []byte, signature []byte, key interface{}) error {
+	expected, _ := method.Sign(message, key)
+	if !bytes.Equal(expected, signature) {
+		return fmt.Errorf("Invalid signature")
+	}
+	return nil
+}
+
+func TestSigningMethodRegistry(test *testing.T) {
+	if _, exists := GetSigningMethod("HS256"); !exists {
+		test.Error("Expected HS256 to be registered")
+	}
+	if _, exists := GetSigningMethod("REV"); exists {
+		test.Error("Expected REV not to be registered")
+	}
+
+	token := NewJWT()
+	token.Header.Set("alg", "REV")
+	if _, err := token.Sign("secret"); err == nil {
+		test.Error("Sign should have failed with unregistered alg")
+	}
+
+	RegisterSigningMethod(&reverseMethod{})
+	defer func() {
+		signingMethodsMutex.Lock()
+		delete(signingMethods, "REV")
+		signingMethodsMutex.Unlock()
+	}()
+
+	compact, err := token.Sign("secret")
+	if err != nil {
+		test.Fatalf("Failed to sign token: %v", err)
+	}
+	if err := token.Verify(compact, "secret"); err != nil {
+		test.Errorf("Failed to verify token: %v", err)
+	}
+	if err := token.Verify(compact+"A", "secret"); err == nil {
+		test.Error("Verify should have failed with invalid signature")
+	}
+}
+
+func TestSigningMethodHMAC(test *testing.T) {
+	message := []byte("message")
+	signature, err := SigningMethodHS256.Sign(message, "secret")
+	if err != nil {
+		test.Fatalf("Failed to sign message: %v", err)
+	}
+	if err := SigningMethodHS256.Verify(message, signature, []byte("secret")); err != nil {
+		test.Errorf("Failed to verify message: %v", err)
+	}
+	if err := SigningMethodHS256.Verify(message, signature, "invalid"); err == nil {
+		test.Error("Verify should have failed with invalid secret")
+	}
+	if _, err := SigningMethodHS256.Sign(message, 42); err == nil {
+		test.Error("Sign should have failed with invalid key type")
+	}
+}
+
+func TestSigningMethodHMACKeySize(test *testing.T) {
+	message := []byte("message")
+	methods := []*SigningMethodHMAC{SigningMethodHS384, SigningMethodHS512}
+	for _, method := range methods {
+		shortKey := make([]byte, method.MinKeySize-1)
+		if _, err := method.Sign(message, shortKey); err == nil {
+			test.Errorf("%v Sign should have failed with a short key", method.Alg)
+		}
+		key := make([]byte, method.MinKeySize)
+		signature, err := method.Sign(message, key)
+		if err != nil {
+			test.Errorf("%v failed to sign message: %v", method.Alg, err)
+			continue
+		}
+		if len(signature) != method.Hash.Size() {
+			test.Errorf("%v expected signature of %v bytes, but got %v instead", method.Alg, method.Hash.Size(), len(signature))
+		}
+		if err := method.Verify(message, signature, shortKey); err == nil {
+			test.Errorf("%v Verify should have failed with a short key", method.Alg)
+		}
+		if err := method.Verify(message, signature, key); err != nil {
+			test.Errorf("%v failed to verify message: %v", method.Alg, err)
+		}
+	}
+}
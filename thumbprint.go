@@ -0,0 +1,85 @@
+package jwt
+
+import (
+	"crypto"
+	"encoding/json"
+	"fmt"
+)
+
+// Thumbprint computes the RFC 7638 thumbprint of the JWK using hash. If hash
+// is zero, SHA256 is used. The thumbprint of a private key is the thumbprint
+// of its public key.
+func (jwk *JWK) Thumbprint(hash crypto.Hash) ([]byte, error) {
+	errMsg := "jwt: JWK.Thumbprint: %v"
+	if hash == 0 {
+		hash = crypto.SHA256
+	}
+	canonical, err := jwk.thumbprintJSON()
+	if err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+	digest, err := hashMessage(hash, canonical)
+	if err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+	return digest, nil
+}
+
+// ThumbprintString returns the base 64 URL encoded RFC 7638 thumbprint of
+// the JWK. If hash is zero, SHA256 is used.
+func (jwk *JWK) ThumbprintString(hash crypto.Hash) (string, error) {
+	thumbprint, err := jwk.Thumbprint(hash)
+	if err != nil {
+		return "", err
+	}
+	return encodeBytes(thumbprint), nil
+}
+
+// thumbprintJSON returns the required members of the JWK in lexicographic
+// order without whitespace as required by RFC 7638 section 3.
+func (jwk *JWK) thumbprintJSON() ([]byte, error) {
+	public := jwk
+	if _, isOct := jwk.Key.([]byte); !isOct {
+		var err error
+		if public, err = jwk.Public(); err != nil {
+			return nil, err
+		}
+	}
+	var raw jwkJSON
+	encoded, err := public.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(encoded, &raw); err != nil {
+		return nil, err
+	}
+
+	switch raw.KeyType {
+	case "oct":
+		return json.Marshal(struct {
+			K       string `json:"k"`
+			KeyType string `json:"kty"`
+		}{raw.K, raw.KeyType})
+	case "RSA":
+		return json.Marshal(struct {
+			E       string `json:"e"`
+			KeyType string `json:"kty"`
+			N       string `json:"n"`
+		}{raw.E, raw.KeyType, raw.N})
+	case "EC":
+		return json.Marshal(struct {
+			Curve   string `json:"crv"`
+			KeyType string `json:"kty"`
+			X       string `json:"x"`
+			Y       string `json:"y"`
+		}{raw.Curve, raw.KeyType, raw.X, raw.Y})
+	case "OKP":
+		return json.Marshal(struct {
+			Curve   string `json:"crv"`
+			KeyType string `json:"kty"`
+			X       string `json:"x"`
+		}{raw.Curve, raw.KeyType, raw.X})
+	default:
+		return nil, fmt.Errorf("Unsupported kty %v", raw.KeyType)
+	}
+}
@@ -0,0 +1,82 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"encoding/json"
+	"testing"
+)
+
+func TestJWKThumbprint(test *testing.T) {
+	//RFC 7638 section 3.1
+	rsaJSON := `{"kty":"RSA",
+		"n":"0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		"e":"AQAB","alg":"RS256","kid":"2011-04-29"}`
+	jwk := &JWK{}
+	if err := json.Unmarshal([]byte(rsaJSON), jwk); err != nil {
+		test.Fatalf("Failed to unmarshal RSA JWK: %v", err)
+	}
+	thumbprint, err := jwk.ThumbprintString(0)
+	if err != nil {
+		test.Fatalf("Failed to compute thumbprint: %v", err)
+	}
+	if thumbprint != "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs" {
+		test.Errorf("Expected RFC 7638 thumbprint, but got %v instead", thumbprint)
+	}
+	sha512Thumbprint, err := jwk.Thumbprint(crypto.SHA512)
+	if err != nil {
+		test.Fatalf("Failed to compute SHA512 thumbprint: %v", err)
+	}
+	if len(sha512Thumbprint) != 64 {
+		test.Errorf("Expected a 64 byte thumbprint, but got %v bytes instead", len(sha512Thumbprint))
+	}
+
+	//RFC 8037 appendix A.3
+	okpJSON := `{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`
+	if err := json.Unmarshal([]byte(okpJSON), jwk); err != nil {
+		test.Fatalf("Failed to unmarshal OKP JWK: %v", err)
+	}
+	if thumbprint, _ := jwk.ThumbprintString(crypto.SHA256); thumbprint != "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k" {
+		test.Errorf("Expected RFC 8037 thumbprint, but got %v instead", thumbprint)
+	}
+}
+
+func TestJWKThumbprintKeyTypes(test *testing.T) {
+	for alg, key := range testJWKKeys(test) {
+		privateJWK, _ := NewJWK(key)
+		privateThumbprint, err := privateJWK.ThumbprintString(0)
+		if err != nil {
+			test.Errorf("Failed to compute %v thumbprint: %v", alg, err)
+			continue
+		}
+		if alg == "HS256" {
+			continue
+		}
+		publicJWK, _ := privateJWK.Public()
+		publicThumbprint, _ := publicJWK.ThumbprintString(0)
+		if privateThumbprint != publicThumbprint {
+			test.Errorf("Expected %v private and public thumbprints to match", alg)
+		}
+	}
+
+	if _, err := (&JWK{Key: "secret"}).Thumbprint(0); err == nil {
+		test.Error("Thumbprint should have failed with a string key")
+	}
+}
+
+func TestHeaderSetKeyThumbprint(test *testing.T) {
+	publicKey, privateKey, _ := ed25519.GenerateKey(nil)
+	token := NewJWT()
+	token.Header.Set("alg", "EdDSA")
+	if err := token.Header.SetKeyThumbprint(privateKey); err != nil {
+		test.Fatalf("Failed to set kid: %v", err)
+	}
+	jwk, _ := NewJWK(publicKey)
+	expected, _ := jwk.ThumbprintString(0)
+	if kid, _ := token.Header.GetString("kid"); kid != expected {
+		test.Errorf("Expected kid to be %v, but got %v instead", expected, kid)
+	}
+	if err := token.Header.SetKeyThumbprint(42); err == nil {
+		test.Error("SetKeyThumbprint should have failed with an invalid key")
+	}
+}
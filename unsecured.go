@@ -0,0 +1,50 @@
+package jwt
+
+import (
+	"fmt"
+)
+
+type unsecuredKey string
+
+// UnsafeAllowNone is the only key accepted by SigningMethodNone. Tokens with
+// alg none can only be produced by signing with this key and only be accepted
+// by verifying with this key and the AllowUnsecured option, so they cannot be
+// produced or accepted by accident.
+const UnsafeAllowNone unsecuredKey = "unsafe: none signing method allowed"
+
+// SigningMethodUnsecured implements the unsecured JWT from RFC 7519 section 6.
+// The signature of an unsecured JWT is empty.
+type SigningMethodUnsecured struct{}
+
+// SigningMethodNone produces and accepts unsecured JWTs with alg none.
+var SigningMethodNone = &SigningMethodUnsecured{}
+
+func init() {
+	RegisterSigningMethod(SigningMethodNone)
+}
+
+// Name returns the alg header value for the method.
+func (method *SigningMethodUnsecured) Name() string {
+	return "none"
+}
+
+// Sign returns an empty signature if key is UnsafeAllowNone.
+func (method *SigningMethodUnsecured) Sign(message []byte, key interface{}) ([]byte, error) {
+	errMsg := "jwt: SigningMethodUnsecured.Sign: %v"
+	if key != UnsafeAllowNone {
+		return nil, fmt.Errorf(errMsg, "alg none requires the UnsafeAllowNone key")
+	}
+	return []byte{}, nil
+}
+
+// Verify checks that signature is empty if key is UnsafeAllowNone.
+func (method *SigningMethodUnsecured) Verify(message []byte, signature []byte, key interface{}) error {
+	errMsg := "jwt: SigningMethodUnsecured.Verify: %v"
+	if key != UnsafeAllowNone {
+		return fmt.Errorf(errMsg, "alg none requires the UnsafeAllowNone key")
+	}
+	if len(signature) != 0 {
+		return fmt.Errorf(errMsg, "Invalid signature")
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package jwt
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJWTUnsecured(test *testing.T) {
+	token := NewJWT()
+	token.Header.Set("alg", "none")
+	token.Claims.SetIssuer("jwt")
+
+	if _, err := token.Sign("secret"); err == nil {
+		test.Error("Sign should have failed with alg none and a secret")
+	}
+	compact, err := token.SignWithKey(UnsafeAllowNone)
+	if err != nil {
+		test.Fatalf("Failed to sign unsecured token: %v", err)
+	}
+	if !strings.HasSuffix(compact, ".") || strings.Count(compact, ".") != 2 {
+		test.Errorf("Expected an empty signature segment, but got %v instead", compact)
+	}
+
+	if _, err := Parse(compact, StaticKey(UnsafeAllowNone)); err == nil {
+		test.Error("Parse should have failed without AllowUnsecured")
+	}
+	if _, err := Parse(compact, StaticKey("secret"), AllowUnsecured()); err == nil {
+		test.Error("Parse should have failed without the UnsafeAllowNone key")
+	}
+	if err := NewJWT().Verify(compact, string(UnsafeAllowNone)); err == nil {
+		test.Error("Verify should have failed with alg none")
+	}
+	parsed, err := Parse(compact, StaticKey(UnsafeAllowNone), AllowUnsecured())
+	if err != nil {
+		test.Fatalf("Failed to parse unsecured token: %v", err)
+	}
+	if iss, _ := parsed.Claims.GetIssuer(); iss != "jwt" {
+		test.Errorf("Expected iss to be jwt, but got %v instead", iss)
+	}
+	if _, err := Parse(compact, StaticKey(UnsafeAllowNone), AllowUnsecured(), WithAlgorithms("HS256")); err == nil {
+		test.Error("Parse should have failed with none missing from the allowed algorithms")
+	}
+	if _, err := Parse(compact+"AAAA", StaticKey(UnsafeAllowNone), AllowUnsecured()); err == nil {
+		test.Error("Parse should have failed with a non empty signature")
+	}
+
+	//A token signed with a real key must not be accepted with the none key
+	token.Header.Set("alg", "HS256")
+	signed, _ := token.Sign("secret")
+	if _, err := Parse(signed, StaticKey(UnsafeAllowNone), AllowUnsecured()); err == nil {
+		test.Error("Parse should have failed with an HS256 token and the UnsafeAllowNone key")
+	}
+}
@@ -0,0 +1,221 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrMissingClaim is returned when a required claim is not present.
+var ErrMissingClaim = errors.New("Claim is missing")
+
+// ErrInvalidClaim is returned when a claim does not have the expected value.
+var ErrInvalidClaim = errors.New("Claim is invalid")
+
+// ClaimError describes a claim that failed validation.
+type ClaimError struct {
+	Claim string
+	Err   error
+}
+
+// Error returns the name of the claim and the reason it failed validation.
+func (err *ClaimError) Error() string {
+	return err.Claim + ": " + err.Err.Error()
+}
+
+// Unwrap returns the reason the claim failed validation.
+func (err *ClaimError) Unwrap() error {
+	return err.Err
+}
+
+// ValidationError is returned by Validator.Validate. It contains every
+// claim that failed validation.
+type ValidationError struct {
+	Failures []*ClaimError
+}
+
+// Error returns all the validation failures.
+func (err *ValidationError) Error() string {
+	failures := make([]string, 0, len(err.Failures))
+	for _, failure := range err.Failures {
+		failures = append(failures, failure.Error())
+	}
+	return "jwt: Validator.Validate: " + strings.Join(failures, "; ")
+}
+
+// Is returns true if any of the validation failures matches target.
+func (err *ValidationError) Is(target error) bool {
+	for _, failure := range err.Failures {
+		if errors.Is(failure, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatorOption configures a Validator.
+type ValidatorOption func(validator *Validator)
+
+// Validator validates Claims against a set of expectations. A Validator is
+// created once with NewValidator and can be used to validate many Claims.
+// The exp, nbf, and iat claims are always validated when present.
+type Validator struct {
+	issuer   string
+	audience string
+	subject  string
+	fallback bool
+	typ      string
+	required []string
+	leeway   time.Duration
+	clock    func() time.Time
+}
+
+// NewValidator creates a new Validator configured with options.
+func NewValidator(options ...ValidatorOption) *Validator {
+	validator := &Validator{clock: time.Now}
+	for _, option := range options {
+		option(validator)
+	}
+	return validator
+}
+
+// WithIssuer requires the iss claim to be iss.
+func WithIssuer(iss string) ValidatorOption {
+	return func(validator *Validator) {
+		validator.issuer = iss
+	}
+}
+
+// WithAudience requires the aud claim to contain aud. The aud claim can be
+// a single string or an array of strings.
+func WithAudience(aud string) ValidatorOption {
+	return func(validator *Validator) {
+		validator.audience = aud
+	}
+}
+
+// WithSubject requires the sub claim to be sub.
+func WithSubject(sub string) ValidatorOption {
+	return func(validator *Validator) {
+		validator.subject = sub
+	}
+}
+
+// WithPrincipalFallback makes the Validator use the legacy prn claim when
+// the sub claim is not present.
+func WithPrincipalFallback() ValidatorOption {
+	return func(validator *Validator) {
+		validator.fallback = true
+	}
+}
+
+// WithType requires the typ claim to be typ.
+func WithType(typ string) ValidatorOption {
+	return func(validator *Validator) {
+		validator.typ = typ
+	}
+}
+
+// WithRequiredClaims requires the claims given by names to be present.
+func WithRequiredClaims(names ...string) ValidatorOption {
+	return func(validator *Validator) {
+		validator.required = append(validator.required, names...)
+	}
+}
+
+// WithLeeway sets the clock skew allowed when validating the exp, nbf, and
+// iat claims.
+func WithLeeway(leeway time.Duration) ValidatorOption {
+	return func(validator *Validator) {
+		validator.leeway = leeway
+	}
+}
+
+// WithClock sets the function used to get the current time. It defaults
+// to time.Now.
+func WithClock(clock func() time.Time) ValidatorOption {
+	return func(validator *Validator) {
+		validator.clock = clock
+	}
+}
+
+// Validate validates claims against the Validator expectations. It returns
+// nil if claims are valid or a *ValidationError with every failure.
+func (validator *Validator) Validate(claims *Claims) error {
+	var failures []*ClaimError
+	fail := func(claim string, err error) {
+		failures = append(failures, &ClaimError{Claim: claim, Err: err})
+	}
+
+	for _, name := range validator.required {
+		if !claims.Has(name) {
+			fail(name, ErrMissingClaim)
+		}
+	}
+
+	now := validator.clock()
+	for _, name := range []string{"exp", "nbf", "iat"} {
+		if err := claims.validateTimeClaim(name, now, validator.leeway); err != nil {
+			fail(name, err)
+		}
+	}
+
+	if validator.issuer != "" {
+		if err := expectString(claims, "iss", validator.issuer); err != nil {
+			fail("iss", err)
+		}
+	}
+	if validator.audience != "" {
+		if err := expectAudience(claims, validator.audience); err != nil {
+			fail("aud", err)
+		}
+	}
+	if validator.subject != "" {
+		name := "sub"
+		if validator.fallback && !claims.Has(name) && claims.Has("prn") {
+			name = "prn"
+		}
+		if err := expectString(claims, name, validator.subject); err != nil {
+			fail(name, err)
+		}
+	}
+	if validator.typ != "" {
+		if err := expectString(claims, "typ", validator.typ); err != nil {
+			fail("typ", err)
+		}
+	}
+
+	if len(failures) > 0 {
+		return &ValidationError{Failures: failures}
+	}
+	return nil
+}
+
+func expectString(claims *Claims, name string, expected string) error {
+	if !claims.Has(name) {
+		return ErrMissingClaim
+	}
+	value, err := claims.GetString(name)
+	if err != nil {
+		return fmt.Errorf("%w: %v is not a string value", ErrInvalidClaim, name)
+	}
+	if value != expected {
+		return fmt.Errorf("%w: expected %v, but got %v", ErrInvalidClaim, expected, value)
+	}
+	return nil
+}
+
+func expectAudience(claims *Claims, expected string) error {
+	if !claims.Has("aud") {
+		return ErrMissingClaim
+	}
+	auds, err := claims.GetAudiences()
+	if err != nil {
+		return fmt.Errorf("%w: aud is not a string or an array of strings", ErrInvalidClaim)
+	}
+	if !claims.HasAudience(expected) {
+		return fmt.Errorf("%w: expected %v in %v", ErrInvalidClaim, expected, auds)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestValidator(test *testing.T) {
+	now := time.Unix(1516239022, 0)
+	validator := NewValidator(
+		WithIssuer("jwt"),
+		WithAudience("USA"),
+		WithSubject("jrpalma"),
+		WithType("access"),
+		WithRequiredClaims("jti", "exp"),
+		WithLeeway(time.Minute),
+		WithClock(func() time.Time { return now }),
+	)
+
+	claims := NewClaims()
+	claims.SetIssuer("jwt")
+	claims.SetAudience("USA")
+	claims.SetSubject("jrpalma")
+	claims.SetType("access")
+	claims.SetJTI("12345")
+	claims.SetIssuedAt(now)
+	claims.SetExpiration(now.Add(-30 * time.Second))
+	if err := validator.Validate(claims); err != nil {
+		test.Errorf("Expected claims to be valid: %v", err)
+	}
+
+	claims.SetIssuer("other")
+	claims.Del("jti")
+	claims.SetExpiration(now.Add(-time.Hour))
+	err := validator.Validate(claims)
+	validationErr, validType := err.(*ValidationError)
+	if !validType {
+		test.Fatalf("Expected a *ValidationError, but got %v instead", err)
+	}
+	if len(validationErr.Failures) != 3 {
+		test.Errorf("Expected 3 failures, but got %v instead: %v", len(validationErr.Failures), err)
+	}
+	failed := make(map[string]error)
+	for _, failure := range validationErr.Failures {
+		failed[failure.Claim] = failure.Err
+	}
+	if !errors.Is(failed["jti"], ErrMissingClaim) {
+		test.Errorf("Expected jti to be missing, but got %v instead", failed["jti"])
+	}
+	if !errors.Is(failed["iss"], ErrInvalidClaim) {
+		test.Errorf("Expected iss to be invalid, but got %v instead", failed["iss"])
+	}
+	if !errors.Is(failed["exp"], ErrTokenExpired) {
+		test.Errorf("Expected exp to be expired, but got %v instead", failed["exp"])
+	}
+	if !errors.Is(err, ErrTokenExpired) {
+		test.Error("Expected the validation error to match ErrTokenExpired")
+	}
+}
+
+func TestValidatorMissingClaims(test *testing.T) {
+	validator := NewValidator(WithIssuer("jwt"), WithAudience("USA"), WithSubject("jrpalma"), WithType("access"))
+	err := validator.Validate(NewClaims())
+	validationErr, validType := err.(*ValidationError)
+	if !validType {
+		test.Fatalf("Expected a *ValidationError, but got %v instead", err)
+	}
+	if len(validationErr.Failures) != 4 {
+		test.Errorf("Expected 4 failures, but got %v instead: %v", len(validationErr.Failures), err)
+	}
+	if !errors.Is(err, ErrMissingClaim) {
+		test.Error("Expected the validation error to match ErrMissingClaim")
+	}
+
+	claims := NewClaims()
+	claims.Set("iss", 42)
+	claims.SetAudience("USA")
+	claims.SetSubject("jrpalma")
+	claims.SetType("access")
+	if err := validator.Validate(claims); !errors.Is(err, ErrInvalidClaim) {
+		test.Errorf("Expected ErrInvalidClaim, but got %v instead", err)
+	}
+
+	if err := NewValidator().Validate(NewClaims()); err != nil {
+		test.Errorf("Expected empty claims to be valid without expectations: %v", err)
+	}
+
+	for _, name := range []string{"exp", "nbf", "iat"} {
+		claims := NewClaims()
+		claims.Set(name, "tomorrow")
+		err := NewValidator().Validate(claims)
+		if !errors.Is(err, ErrInvalidClaim) {
+			test.Errorf("Expected ErrInvalidClaim for an invalid %v, but got %v instead", name, err)
+		}
+		if validationErr, validType := err.(*ValidationError); !validType || validationErr.Failures[0].Claim != name {
+			test.Errorf("Expected a failure for %v, but got %v instead", name, err)
+		}
+	}
+}
+
+func TestValidatorAudiences(test *testing.T) {
+	validator := NewValidator(WithAudience("billing"))
+	claims := NewClaims()
+	claims.SetAudiences([]string{"api", "billing"})
+	if err := validator.Validate(claims); err != nil {
+		test.Errorf("Expected claims to be valid: %v", err)
+	}
+	claims.SetAudiences([]string{"api"})
+	if err := validator.Validate(claims); !errors.Is(err, ErrInvalidClaim) {
+		test.Errorf("Expected ErrInvalidClaim, but got %v instead", err)
+	}
+}
+
+func TestValidatorPrincipalFallback(test *testing.T) {
+	claims := NewClaims()
+	claims.SetPrincipal("jrpalma")
+	if err := NewValidator(WithSubject("jrpalma")).Validate(claims); !errors.Is(err, ErrMissingClaim) {
+		test.Errorf("Expected ErrMissingClaim, but got %v instead", err)
+	}
+	validator := NewValidator(WithSubject("jrpalma"), WithPrincipalFallback())
+	if err := validator.Validate(claims); err != nil {
+		test.Errorf("Expected prn to be used as the subject: %v", err)
+	}
+	claims.SetSubject("other")
+	if err := validator.Validate(claims); !errors.Is(err, ErrInvalidClaim) {
+		test.Errorf("Expected sub to take precedence over prn, but got %v instead", err)
+	}
+}
@@ -0,0 +1,97 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"fmt"
+	"time"
+)
+
+// CertificateChainKeys is a KeyProvider that verifies tokens with the key of
+// the leaf certificate in their x5c header. The chain in the x5c header must
+// be valid for the Roots pool. If the token also has x5t or x5t#S256
+// headers, they must match the leaf certificate.
+type CertificateChainKeys struct {
+	// Roots are the trusted root certificates. It must not be nil.
+	Roots *x509.CertPool
+	// KeyUsages are the extended key usages the leaf certificate must have.
+	// If empty, any key usage is accepted.
+	KeyUsages []x509.ExtKeyUsage
+	// CurrentTime is the time used to check the validity of the chain. If
+	// zero, the current time is used.
+	CurrentTime time.Time
+}
+
+// NewCertificateChainKeys creates a new CertificateChainKeys that trusts
+// the certificates in roots.
+func NewCertificateChainKeys(roots *x509.CertPool) *CertificateChainKeys {
+	return &CertificateChainKeys{Roots: roots}
+}
+
+// Key verifies the x5c chain of the token like KeyFromHeader. The claims are
+// ignored.
+func (provider *CertificateChainKeys) Key(header *Header, claims *Claims) (interface{}, error) {
+	return provider.KeyFromHeader(header)
+}
+
+// KeyFromHeader verifies the x5c chain of the token and returns the public
+// key of the leaf certificate.
+func (provider *CertificateChainKeys) KeyFromHeader(header *Header) (interface{}, error) {
+	errMsg := "jwt: CertificateChainKeys.Key: %v"
+	if provider.Roots == nil {
+		return nil, fmt.Errorf(errMsg, "No root certificates")
+	}
+	certs, err := header.GetCertificateChain()
+	if err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+	leaf := certs[0]
+	if err := checkCertificateThumbprints(header, leaf); err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	keyUsages := provider.KeyUsages
+	if len(keyUsages) == 0 {
+		keyUsages = []x509.ExtKeyUsage{x509.ExtKeyUsageAny}
+	}
+	options := x509.VerifyOptions{
+		Roots:         provider.Roots,
+		Intermediates: intermediates,
+		KeyUsages:     keyUsages,
+		CurrentTime:   provider.CurrentTime,
+	}
+	if _, err := leaf.Verify(options); err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+	return leaf.PublicKey, nil
+}
+
+func checkCertificateThumbprints(header *Header, leaf *x509.Certificate) error {
+	if header.Has("x5t") {
+		thumbprint, err := header.GetCertificateThumbprint()
+		if err != nil {
+			return err
+		}
+		sum := sha1.Sum(leaf.Raw)
+		if !bytes.Equal(thumbprint, sum[:]) {
+			return fmt.Errorf("x5t does not match the x5c certificate")
+		}
+	}
+	if header.Has("x5t#S256") {
+		thumbprint, err := header.GetCertificateThumbprintS256()
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(leaf.Raw)
+		if !bytes.Equal(thumbprint, sum[:]) {
+			return fmt.Errorf("x5t#S256 does not match the x5c certificate")
+		}
+	}
+	return nil
+}
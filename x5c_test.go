@@ -0,0 +1,109 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestHeaderCertificates(test *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	cert := newTestCertificate(test, "leaf", &key.PublicKey, nil, key, false)
+	header := NewHeader()
+	header.SetCertificateChain([]*x509.Certificate{cert})
+	header.SetCertificateThumbprint(cert)
+	header.SetCertificateThumbprintS256(cert)
+	header.SetCertificateURL("https://example.com/chain.pem")
+
+	jsonBytes, _ := json.Marshal(header.values)
+	decoded := NewHeader()
+	if err := decoded.Unmarshal(jsonBytes); err != nil {
+		test.Fatalf("Failed to unmarshal header: %v", err)
+	}
+	certs, err := decoded.GetCertificateChain()
+	if err != nil {
+		test.Fatalf("Failed to get x5c: %v", err)
+	}
+	if len(certs) != 1 || !certs[0].Equal(cert) {
+		test.Errorf("Expected x5c to contain the leaf certificate")
+	}
+	if thumbprint, err := decoded.GetCertificateThumbprint(); err != nil || len(thumbprint) != 20 {
+		test.Errorf("Expected a 20 byte x5t, but got %v bytes: %v", len(thumbprint), err)
+	}
+	if thumbprint, err := decoded.GetCertificateThumbprintS256(); err != nil || len(thumbprint) != 32 {
+		test.Errorf("Expected a 32 byte x5t#S256, but got %v bytes: %v", len(thumbprint), err)
+	}
+	if url, err := decoded.GetCertificateURL(); err != nil || url != "https://example.com/chain.pem" {
+		test.Errorf("Expected x5u to survive a round trip, but got %v: %v", url, err)
+	}
+
+	invalid := []interface{}{"MIIB", []interface{}{}, []interface{}{1}, []interface{}{"not base64!"}, []string{"AAAA"}}
+	for _, value := range invalid {
+		header.Set("x5c", value)
+		if _, err := header.GetCertificateChain(); err == nil {
+			test.Errorf("GetCertificateChain should have failed with %v", value)
+		}
+	}
+	if _, err := NewHeader().GetCertificateChain(); err == nil {
+		test.Error("GetCertificateChain should have failed without x5c")
+	}
+}
+
+func TestCertificateChainKeys(test *testing.T) {
+	rootKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	intermediateKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	leafKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	root := newTestCertificate(test, "root", &rootKey.PublicKey, nil, rootKey, true)
+	intermediate := newTestCertificate(test, "intermediate", &intermediateKey.PublicKey, root, rootKey, true)
+	leaf := newTestCertificate(test, "leaf", &leafKey.PublicKey, intermediate, intermediateKey, false)
+	roots := x509.NewCertPool()
+	roots.AddCert(root)
+
+	token := NewJWT()
+	token.Header.Set("alg", "ES256")
+	token.Header.SetCertificateChain([]*x509.Certificate{leaf, intermediate})
+	token.Header.SetCertificateThumbprintS256(leaf)
+	compact, err := token.SignWithKey(leafKey)
+	if err != nil {
+		test.Fatalf("Failed to sign token: %v", err)
+	}
+	if _, err := Parse(compact, NewCertificateChainKeys(roots)); err != nil {
+		test.Errorf("Failed to verify token with x5c chain: %v", err)
+	}
+
+	//The chain must lead to one of the roots
+	if _, err := Parse(compact, NewCertificateChainKeys(x509.NewCertPool())); err == nil {
+		test.Error("Parse should have failed with an untrusted chain")
+	}
+	if _, err := Parse(compact, NewCertificateChainKeys(nil)); err == nil {
+		test.Error("Parse should have failed without roots")
+	}
+	expired := &CertificateChainKeys{Roots: roots, CurrentTime: time.Now().Add(2 * time.Hour)}
+	if _, err := Parse(compact, expired); err == nil {
+		test.Error("Parse should have failed with an expired chain")
+	}
+
+	//The token must be signed by the leaf key
+	forged, _ := token.SignWithKey(intermediateKey)
+	if _, err := Parse(forged, NewCertificateChainKeys(roots)); err == nil {
+		test.Error("Parse should have failed with a token not signed by the leaf key")
+	}
+
+	//Thumbprints must match the leaf certificate
+	token.Header.SetCertificateThumbprint(intermediate)
+	mismatched, _ := token.SignWithKey(leafKey)
+	if _, err := Parse(mismatched, NewCertificateChainKeys(roots)); err == nil {
+		test.Error("Parse should have failed with an x5t that does not match the leaf")
+	}
+	token.Header.Del("x5t")
+
+	token.Header.Del("x5c")
+	noChain, _ := token.SignWithKey(leafKey)
+	if _, err := Parse(noChain, NewCertificateChainKeys(roots)); err == nil {
+		test.Error("Parse should have failed without x5c")
+	}
+}